@@ -0,0 +1,108 @@
+// ansi.go
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+var (
+	// ansiSequence matches CSI escape sequences such as "\x1b[31;1m" or "\x1b[0K".
+	ansiSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[@-~]`)
+
+	// sectionMarker matches the collapsible section markers GitLab embeds in
+	// job traces, e.g. "section_start:1700000000:step_script\r".
+	sectionMarker = regexp.MustCompile(`section_(?:start|end):\d+:[^\r\n]*\r`)
+)
+
+// ansiToTview converts a raw job trace into text for a TextView with dynamic
+// colors enabled. ANSI SGR codes become tview color tags, other escape
+// sequences and GitLab section markers are dropped, and literal square
+// brackets in the log are escaped so they are not taken for tags.
+func ansiToTview(trace string) string {
+	text, _ := highlightTrace(trace, "")
+	return text
+}
+
+// highlightTrace is like ansiToTview but additionally wraps every
+// case-insensitive match of term in a numbered region ("0", "1", ...) for the
+// TextView to highlight. It returns the converted text and the match count.
+func highlightTrace(trace, term string) (string, int) {
+	trace = sectionMarker.ReplaceAllString(trace, "")
+
+	var matcher *regexp.Regexp
+	if term != "" {
+		matcher = regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+	}
+
+	var out strings.Builder
+	matches := 0
+
+	writeText := func(text string) {
+		if matcher == nil {
+			out.WriteString(tview.Escape(text))
+			return
+		}
+
+		last := 0
+		for _, loc := range matcher.FindAllStringIndex(text, -1) {
+			out.WriteString(tview.Escape(text[last:loc[0]]))
+			fmt.Fprintf(&out, `["%d"]%s[""]`, matches, tview.Escape(text[loc[0]:loc[1]]))
+			matches++
+			last = loc[1]
+		}
+		out.WriteString(tview.Escape(text[last:]))
+	}
+
+	last := 0
+	for _, loc := range ansiSequence.FindAllStringIndex(trace, -1) {
+		writeText(trace[last:loc[0]])
+		out.WriteString(trace[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	writeText(trace[last:])
+
+	return tview.TranslateANSI(out.String()), matches
+}
+
+// numberLines prefixes every line of text converted by ansiToTview with its
+// number, counting from first. It returns the numbered text and the number of
+// the line after it.
+func numberLines(text string, first int) (string, int) {
+	var out strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+		fmt.Fprintf(&out, "[::d]%5d[::-] %s", first, line)
+		first++
+	}
+	return out.String(), first
+}
+
+// matchLine returns the line of a job trace, without escape sequences, that
+// holds the match of term numbered index by highlightTrace.
+func matchLine(trace, term string, index int) (string, bool) {
+	if term == "" {
+		return "", false
+	}
+
+	matcher := regexp.MustCompile("(?i)" + regexp.QuoteMeta(term))
+	trace = sectionMarker.ReplaceAllString(trace, "")
+
+	// Matches are counted per text between escape sequences, the same way
+	// highlightTrace numbers them.
+	matches := 0
+	for _, line := range strings.Split(trace, "\n") {
+		for _, text := range ansiSequence.Split(line, -1) {
+			matches += len(matcher.FindAllStringIndex(text, -1))
+		}
+		if matches > index {
+			return strings.TrimRight(ansiSequence.ReplaceAllString(line, ""), "\r"), true
+		}
+	}
+	return "", false
+}
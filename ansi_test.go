@@ -0,0 +1,36 @@
+// ansi_test.go
+package main
+
+import "testing"
+
+func TestMatchLine(t *testing.T) {
+	trace := "section_start:1700000000:build\r\x1b[0KBuilding app\n" +
+		"\x1b[31mERROR\x1b[0m: error in main.go\r\n" +
+		"done\n"
+
+	tests := []struct {
+		name   string
+		term   string
+		index  int
+		want   string
+		wantOK bool
+	}{
+		{name: "first match", term: "build", want: "Building app", wantOK: true},
+		{name: "ignores case", term: "DONE", want: "done", wantOK: true},
+		{name: "without escape sequences", term: "error", want: "ERROR: error in main.go", wantOK: true},
+		{name: "second match on the same line", term: "error", index: 1, want: "ERROR: error in main.go", wantOK: true},
+		{name: "past the last match", term: "error", index: 2},
+		{name: "section markers aren't matched", term: "section_start"},
+		{name: "no term", term: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := matchLine(trace, tt.term, tt.index)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("matchLine(%q, %d) = %q, %v, want %q, %v", tt.term, tt.index, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// bridges.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// listPipelineBridges returns the bridge jobs of a pipeline: the jobs that
+// trigger child or multi-project pipelines.
+func listPipelineBridges(ctx context.Context, gl GitLab, projectID, pipelineID string) ([]*gitlab.Bridge, error) {
+	id, err := toInt(pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching downstream pipelines for project %s: %w", projectID, err)
+	}
+
+	var bridges []*gitlab.Bridge
+	listOptions := &gitlab.ListJobsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: perPage,
+			Page:    1,
+		},
+	}
+
+	for {
+		page, resp, err := gl.ListPipelineBridges(ctx, projectID, id, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching downstream pipelines for project %s and pipeline %s: %w", projectID, pipelineID, err)
+		}
+
+		bridges = append(bridges, page...)
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+
+	return bridges, nil
+}
+
+// bridgeInfo returns the text of a bridge job in the stage list. The
+// downstream project is named when it isn't the project of the pipeline.
+func bridgeInfo(bridge *gitlab.Bridge, projectID string) string {
+	text := fmt.Sprintf("Downstream: %s \nStage: %s \nStatus: %s%s[-] \n",
+		tview.Escape(bridge.Name), tview.Escape(bridge.Stage), statusColor(bridge.Status), bridge.Status)
+
+	downstream := bridge.DownstreamPipeline
+	if downstream == nil {
+		return text + "Pipeline: not created \n"
+	}
+	if strconv.Itoa(downstream.ProjectID) != projectID {
+		text += fmt.Sprintf("Project: %s \n", tview.Escape(downstreamProjectPath(downstream)))
+	}
+	return text + fmt.Sprintf("Pipeline: #%d %s%s[-] \n", downstream.ID, statusColor(downstream.Status), downstream.Status)
+}
+
+// downstreamProjectPath returns the path of the project of a downstream
+// pipeline, taken from its web URL, e.g. "mygroup/app" from
+// "https://gitlab.com/mygroup/app/-/pipelines/12".
+func downstreamProjectPath(pipeline *gitlab.PipelineInfo) string {
+	path := strings.TrimPrefix(pipeline.WebURL, strings.TrimSuffix(gitlabURL, "/")+"/")
+	if i := strings.Index(path, "/-/"); i > 0 && path != pipeline.WebURL {
+		return path[:i]
+	}
+	return fmt.Sprintf("project %d", pipeline.ProjectID)
+}
+
+// showDownstreamPipeline lists the stages of the pipeline bridge triggered.
+// crumbs is the breadcrumb of the project of the upstream pipeline.
+func showDownstreamPipeline(app *tview.Application, bridge *gitlab.Bridge, projectID string, crumbs []string, returnTo tview.Primitive) {
+	downstream := bridge.DownstreamPipeline
+	if downstream == nil {
+		showMessage(app, "This job hasn't triggered a pipeline yet.", returnTo)
+		return
+	}
+
+	downstreamID := strconv.Itoa(downstream.ProjectID)
+	if downstreamID != projectID {
+		crumbs = append([]string{instanceCrumb()}, strings.Split(downstreamProjectPath(downstream), "/")...)
+	}
+
+	fetchAndShowStages(app, downstreamID, strconv.Itoa(downstream.ID), downstream.Ref, crumbs, returnTo)
+}
@@ -0,0 +1,138 @@
+// cache.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+const defaultCacheTTL = 30 * time.Second
+
+// cachingGitLab keeps the results of list calls for ttl, so going back and
+// forth between views doesn't fetch the same lists again. Other calls go
+// straight to the wrapped GitLab.
+type cachingGitLab struct {
+	GitLab
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value  interface{}
+	resp   *gitlab.Response
+	stored time.Time
+}
+
+func newCachingGitLab(gl GitLab, ttl time.Duration) *cachingGitLab {
+	return &cachingGitLab{GitLab: gl, ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// clear drops all cached results.
+func (c *cachingGitLab) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+}
+
+// clearCache drops the cached results of the current client, so the next
+// calls fetch fresh data.
+func clearCache() {
+	if c, ok := gitlabClient.(*cachingGitLab); ok {
+		c.clear()
+	}
+}
+
+// cached returns the result stored for the call described by key, calling
+// fetch and storing its result if there is none or it's expired. Errors are
+// not cached.
+func cached[T any](c *cachingGitLab, key []interface{}, fetch func() (T, *gitlab.Response, error)) (T, *gitlab.Response, error) {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return fetch()
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[string(k)]
+	c.mu.Unlock()
+	if ok && time.Since(entry.stored) < c.ttl {
+		return entry.value.(T), entry.resp, nil
+	}
+
+	value, resp, err := fetch()
+	if err != nil {
+		return value, resp, err
+	}
+
+	c.mu.Lock()
+	c.entries[string(k)] = cacheEntry{value: value, resp: resp, stored: time.Now()}
+	c.mu.Unlock()
+
+	return value, resp, nil
+}
+
+func (c *cachingGitLab) ListGroups(ctx context.Context, opt *gitlab.ListGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error) {
+	return cached(c, []interface{}{"groups", opt}, func() ([]*gitlab.Group, *gitlab.Response, error) {
+		return c.GitLab.ListGroups(ctx, opt)
+	})
+}
+
+func (c *cachingGitLab) ListSubGroups(ctx context.Context, gid interface{}, opt *gitlab.ListSubGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error) {
+	return cached(c, []interface{}{"subgroups", fmt.Sprint(gid), opt}, func() ([]*gitlab.Group, *gitlab.Response, error) {
+		return c.GitLab.ListSubGroups(ctx, gid, opt)
+	})
+}
+
+func (c *cachingGitLab) ListGroupProjects(ctx context.Context, gid interface{}, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+	return cached(c, []interface{}{"projects", fmt.Sprint(gid), opt}, func() ([]*gitlab.Project, *gitlab.Response, error) {
+		return c.GitLab.ListGroupProjects(ctx, gid, opt)
+	})
+}
+
+func (c *cachingGitLab) ListProjects(ctx context.Context, opt *gitlab.ListProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+	return cached(c, []interface{}{"member projects", opt}, func() ([]*gitlab.Project, *gitlab.Response, error) {
+		return c.GitLab.ListProjects(ctx, opt)
+	})
+}
+
+func (c *cachingGitLab) ListBranches(ctx context.Context, pid interface{}, opt *gitlab.ListBranchesOptions) ([]*gitlab.Branch, *gitlab.Response, error) {
+	return cached(c, []interface{}{"branches", fmt.Sprint(pid), opt}, func() ([]*gitlab.Branch, *gitlab.Response, error) {
+		return c.GitLab.ListBranches(ctx, pid, opt)
+	})
+}
+
+func (c *cachingGitLab) ListProjectPipelines(ctx context.Context, pid interface{}, opt *gitlab.ListProjectPipelinesOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+	return cached(c, []interface{}{"pipelines", fmt.Sprint(pid), opt}, func() ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+		return c.GitLab.ListProjectPipelines(ctx, pid, opt)
+	})
+}
+
+func (c *cachingGitLab) ListProjectMergeRequests(ctx context.Context, pid interface{}, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+	return cached(c, []interface{}{"merge requests", fmt.Sprint(pid), opt}, func() ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+		return c.GitLab.ListProjectMergeRequests(ctx, pid, opt)
+	})
+}
+
+func (c *cachingGitLab) ListMergeRequestPipelines(ctx context.Context, pid interface{}, mergeRequest int, opt *gitlab.ListOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+	return cached(c, []interface{}{"merge request pipelines", fmt.Sprint(pid), mergeRequest, opt}, func() ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+		return c.GitLab.ListMergeRequestPipelines(ctx, pid, mergeRequest, opt)
+	})
+}
+
+func (c *cachingGitLab) ListEnvironments(ctx context.Context, pid interface{}, opt *gitlab.ListEnvironmentsOptions) ([]*gitlab.Environment, *gitlab.Response, error) {
+	return cached(c, []interface{}{"environments", fmt.Sprint(pid), opt}, func() ([]*gitlab.Environment, *gitlab.Response, error) {
+		return c.GitLab.ListEnvironments(ctx, pid, opt)
+	})
+}
+
+func (c *cachingGitLab) ListProjectDeployments(ctx context.Context, pid interface{}, opt *gitlab.ListProjectDeploymentsOptions) ([]*gitlab.Deployment, *gitlab.Response, error) {
+	return cached(c, []interface{}{"deployments", fmt.Sprint(pid), opt}, func() ([]*gitlab.Deployment, *gitlab.Response, error) {
+		return c.GitLab.ListProjectDeployments(ctx, pid, opt)
+	})
+}
@@ -0,0 +1,304 @@
+// client.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/xanzy/go-gitlab"
+)
+
+// GitLab is the subset of the GitLab API used by the viewer. Views talk to
+// GitLab only through it so that tests can substitute a fake.
+type GitLab interface {
+	ListGroups(ctx context.Context, opt *gitlab.ListGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error)
+	ListSubGroups(ctx context.Context, gid interface{}, opt *gitlab.ListSubGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error)
+	ListGroupProjects(ctx context.Context, gid interface{}, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error)
+	GetProject(ctx context.Context, pid interface{}) (*gitlab.Project, *gitlab.Response, error)
+	ListProjects(ctx context.Context, opt *gitlab.ListProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error)
+	ListBranches(ctx context.Context, pid interface{}, opt *gitlab.ListBranchesOptions) ([]*gitlab.Branch, *gitlab.Response, error)
+	ListProjectPipelines(ctx context.Context, pid interface{}, opt *gitlab.ListProjectPipelinesOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error)
+	CreatePipeline(ctx context.Context, pid interface{}, opt *gitlab.CreatePipelineOptions) (*gitlab.Pipeline, *gitlab.Response, error)
+	GetPipeline(ctx context.Context, pid interface{}, pipelineID int) (*gitlab.Pipeline, *gitlab.Response, error)
+	GetPipelineVariables(ctx context.Context, pid interface{}, pipelineID int) ([]*gitlab.PipelineVariable, *gitlab.Response, error)
+	ListProjectVariables(ctx context.Context, pid interface{}, opt *gitlab.ListProjectVariablesOptions) ([]*gitlab.ProjectVariable, *gitlab.Response, error)
+	RetryPipelineBuild(ctx context.Context, pid interface{}, pipelineID int) (*gitlab.Pipeline, *gitlab.Response, error)
+	CancelPipelineBuild(ctx context.Context, pid interface{}, pipelineID int) (*gitlab.Pipeline, *gitlab.Response, error)
+	ListProjectMergeRequests(ctx context.Context, pid interface{}, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error)
+	ListMergeRequestPipelines(ctx context.Context, pid interface{}, mergeRequest int, opt *gitlab.ListOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error)
+	GetCommit(ctx context.Context, pid interface{}, sha string) (*gitlab.Commit, *gitlab.Response, error)
+	ListEnvironments(ctx context.Context, pid interface{}, opt *gitlab.ListEnvironmentsOptions) ([]*gitlab.Environment, *gitlab.Response, error)
+	ListProjectDeployments(ctx context.Context, pid interface{}, opt *gitlab.ListProjectDeploymentsOptions) ([]*gitlab.Deployment, *gitlab.Response, error)
+	ProjectLint(ctx context.Context, pid interface{}, opt *gitlab.ProjectLintOptions) (*gitlab.ProjectLintResult, *gitlab.Response, error)
+	ListPipelineJobs(ctx context.Context, pid interface{}, pipelineID int, opt *gitlab.ListJobsOptions) ([]*gitlab.Job, *gitlab.Response, error)
+	ListPipelineBridges(ctx context.Context, pid interface{}, pipelineID int, opt *gitlab.ListJobsOptions) ([]*gitlab.Bridge, *gitlab.Response, error)
+	GetJob(ctx context.Context, pid interface{}, jobID int) (*gitlab.Job, *gitlab.Response, error)
+	GetTraceFile(ctx context.Context, pid interface{}, jobID int) (*bytes.Reader, *gitlab.Response, error)
+	GetJobArtifacts(ctx context.Context, pid interface{}, jobID int) (*bytes.Reader, *gitlab.Response, error)
+	RetryJob(ctx context.Context, pid interface{}, jobID int) (*gitlab.Job, *gitlab.Response, error)
+	CancelJob(ctx context.Context, pid interface{}, jobID int) (*gitlab.Job, *gitlab.Response, error)
+	PlayJob(ctx context.Context, pid interface{}, jobID int, opt *gitlab.PlayJobOptions) (*gitlab.Job, *gitlab.Response, error)
+	CurrentUser(ctx context.Context) (*gitlab.User, *gitlab.Response, error)
+}
+
+// maxConcurrencyLimit is the most requests background fetches may be
+// configured to run at once.
+const maxConcurrencyLimit = 32
+
+var (
+	// perPage is the page size used to fetch whole lists.
+	perPage = 100
+
+	// maxConcurrency bounds how many requests background fetches, such as
+	// counting the jobs of pipelines, run at once.
+	maxConcurrency = 4
+)
+
+// clientAdapter implements GitLab on top of a go-gitlab client.
+type clientAdapter struct {
+	client *gitlab.Client
+}
+
+func newGitLab(client *gitlab.Client) GitLab {
+	return &clientAdapter{client: client}
+}
+
+func (c *clientAdapter) ListGroups(ctx context.Context, opt *gitlab.ListGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.Group, *gitlab.Response, error) {
+		return c.client.Groups.ListGroups(opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListSubGroups(ctx context.Context, gid interface{}, opt *gitlab.ListSubGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.Group, *gitlab.Response, error) {
+		return c.client.Groups.ListSubGroups(gid, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListGroupProjects(ctx context.Context, gid interface{}, opt *gitlab.ListGroupProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.Project, *gitlab.Response, error) {
+		return c.client.Groups.ListGroupProjects(gid, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) GetProject(ctx context.Context, pid interface{}) (*gitlab.Project, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.Project, *gitlab.Response, error) {
+		return c.client.Projects.GetProject(pid, nil, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListProjects(ctx context.Context, opt *gitlab.ListProjectsOptions) ([]*gitlab.Project, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.Project, *gitlab.Response, error) {
+		return c.client.Projects.ListProjects(opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListBranches(ctx context.Context, pid interface{}, opt *gitlab.ListBranchesOptions) ([]*gitlab.Branch, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.Branch, *gitlab.Response, error) {
+		return c.client.Branches.ListBranches(pid, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListProjectPipelines(ctx context.Context, pid interface{}, opt *gitlab.ListProjectPipelinesOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+		return c.client.Pipelines.ListProjectPipelines(pid, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) CreatePipeline(ctx context.Context, pid interface{}, opt *gitlab.CreatePipelineOptions) (*gitlab.Pipeline, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.Pipeline, *gitlab.Response, error) {
+		return c.client.Pipelines.CreatePipeline(pid, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) GetPipeline(ctx context.Context, pid interface{}, pipelineID int) (*gitlab.Pipeline, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.Pipeline, *gitlab.Response, error) {
+		return c.client.Pipelines.GetPipeline(pid, pipelineID, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) GetPipelineVariables(ctx context.Context, pid interface{}, pipelineID int) ([]*gitlab.PipelineVariable, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.PipelineVariable, *gitlab.Response, error) {
+		return c.client.Pipelines.GetPipelineVariables(pid, pipelineID, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListProjectVariables(ctx context.Context, pid interface{}, opt *gitlab.ListProjectVariablesOptions) ([]*gitlab.ProjectVariable, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.ProjectVariable, *gitlab.Response, error) {
+		return c.client.ProjectVariables.ListVariables(pid, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) RetryPipelineBuild(ctx context.Context, pid interface{}, pipelineID int) (*gitlab.Pipeline, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.Pipeline, *gitlab.Response, error) {
+		return c.client.Pipelines.RetryPipelineBuild(pid, pipelineID, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) CancelPipelineBuild(ctx context.Context, pid interface{}, pipelineID int) (*gitlab.Pipeline, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.Pipeline, *gitlab.Response, error) {
+		return c.client.Pipelines.CancelPipelineBuild(pid, pipelineID, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListProjectMergeRequests(ctx context.Context, pid interface{}, opt *gitlab.ListProjectMergeRequestsOptions) ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.MergeRequest, *gitlab.Response, error) {
+		return c.client.MergeRequests.ListProjectMergeRequests(pid, opt, gitlab.WithContext(ctx))
+	})
+}
+
+// ListMergeRequestPipelines takes list options although go-gitlab doesn't;
+// they're added to the request's query instead.
+func (c *clientAdapter) ListMergeRequestPipelines(ctx context.Context, pid interface{}, mergeRequest int, opt *gitlab.ListOptions) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+	withListOptions := func(req *retryablehttp.Request) error {
+		query := req.URL.Query()
+		query.Set("page", strconv.Itoa(opt.Page))
+		query.Set("per_page", strconv.Itoa(opt.PerPage))
+		req.URL.RawQuery = query.Encode()
+		return nil
+	}
+	return retryRateLimited(ctx, func() ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+		return c.client.MergeRequests.ListMergeRequestPipelines(pid, mergeRequest, gitlab.WithContext(ctx), withListOptions)
+	})
+}
+
+func (c *clientAdapter) GetCommit(ctx context.Context, pid interface{}, sha string) (*gitlab.Commit, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.Commit, *gitlab.Response, error) {
+		return c.client.Commits.GetCommit(pid, sha, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListEnvironments(ctx context.Context, pid interface{}, opt *gitlab.ListEnvironmentsOptions) ([]*gitlab.Environment, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.Environment, *gitlab.Response, error) {
+		return c.client.Environments.ListEnvironments(pid, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListProjectDeployments(ctx context.Context, pid interface{}, opt *gitlab.ListProjectDeploymentsOptions) ([]*gitlab.Deployment, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.Deployment, *gitlab.Response, error) {
+		return c.client.Deployments.ListProjectDeployments(pid, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ProjectLint(ctx context.Context, pid interface{}, opt *gitlab.ProjectLintOptions) (*gitlab.ProjectLintResult, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.ProjectLintResult, *gitlab.Response, error) {
+		return c.client.Validate.ProjectLint(pid, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListPipelineJobs(ctx context.Context, pid interface{}, pipelineID int, opt *gitlab.ListJobsOptions) ([]*gitlab.Job, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.Job, *gitlab.Response, error) {
+		return c.client.Jobs.ListPipelineJobs(pid, pipelineID, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) ListPipelineBridges(ctx context.Context, pid interface{}, pipelineID int, opt *gitlab.ListJobsOptions) ([]*gitlab.Bridge, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() ([]*gitlab.Bridge, *gitlab.Response, error) {
+		return c.client.Jobs.ListPipelineBridges(pid, pipelineID, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) GetJob(ctx context.Context, pid interface{}, jobID int) (*gitlab.Job, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.Job, *gitlab.Response, error) {
+		return c.client.Jobs.GetJob(pid, jobID, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) GetTraceFile(ctx context.Context, pid interface{}, jobID int) (*bytes.Reader, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*bytes.Reader, *gitlab.Response, error) {
+		return c.client.Jobs.GetTraceFile(pid, jobID, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) GetJobArtifacts(ctx context.Context, pid interface{}, jobID int) (*bytes.Reader, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*bytes.Reader, *gitlab.Response, error) {
+		return c.client.Jobs.GetJobArtifacts(pid, jobID, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) RetryJob(ctx context.Context, pid interface{}, jobID int) (*gitlab.Job, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.Job, *gitlab.Response, error) {
+		return c.client.Jobs.RetryJob(pid, jobID, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) CancelJob(ctx context.Context, pid interface{}, jobID int) (*gitlab.Job, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.Job, *gitlab.Response, error) {
+		return c.client.Jobs.CancelJob(pid, jobID, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) PlayJob(ctx context.Context, pid interface{}, jobID int, opt *gitlab.PlayJobOptions) (*gitlab.Job, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.Job, *gitlab.Response, error) {
+		return c.client.Jobs.PlayJob(pid, jobID, opt, gitlab.WithContext(ctx))
+	})
+}
+
+func (c *clientAdapter) CurrentUser(ctx context.Context) (*gitlab.User, *gitlab.Response, error) {
+	return retryRateLimited(ctx, func() (*gitlab.User, *gitlab.Response, error) {
+		return c.client.Users.CurrentUser(gitlab.WithContext(ctx))
+	})
+}
+
+// isAccessDenied reports whether err is GitLab refusing a request with 401
+// or 403, e.g. for the pipelines of a project the user can only see.
+func isAccessDenied(err error) bool {
+	var errResp *gitlab.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+	return errResp.Response.StatusCode == http.StatusUnauthorized || errResp.Response.StatusCode == http.StatusForbidden
+}
+
+// maxRateLimitRetries is how often a rate limited request is retried.
+const maxRateLimitRetries = 3
+
+// maxRateLimitWait is the longest wait before retrying a rate limited
+// request, whatever GitLab asks for.
+const maxRateLimitWait = time.Minute
+
+// retryRateLimited calls call, retrying it after the wait GitLab asks for
+// when it's rate limited. The wait is reported as the status of ctx.
+func retryRateLimited[T any](ctx context.Context, call func() (T, *gitlab.Response, error)) (T, *gitlab.Response, error) {
+	for retry := 0; ; retry++ {
+		value, resp, err := call()
+		if resp == nil || resp.StatusCode != http.StatusTooManyRequests || retry == maxRateLimitRetries {
+			return value, resp, err
+		}
+
+		wait := rateLimitWait(resp, retry)
+		reportStatus(ctx, fmt.Sprintf("Rate limited by GitLab, retrying in %s...", wait))
+
+		select {
+		case <-ctx.Done():
+			return value, resp, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// rateLimitWait returns how long to wait before retrying a rate limited
+// request, taken from the Retry-After or RateLimit-Reset headers if they're
+// set and doubling with every retry otherwise, but at most maxRateLimitWait.
+func rateLimitWait(resp *gitlab.Response, retry int) time.Duration {
+	wait := time.Second << retry
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		wait = time.Duration(seconds) * time.Second
+	} else if reset, err := strconv.ParseInt(resp.Header.Get("RateLimit-Reset"), 10, 64); err == nil {
+		if untilReset := time.Until(time.Unix(reset, 0)); untilReset > 0 {
+			wait = untilReset
+		}
+	}
+
+	if wait > maxRateLimitWait {
+		wait = maxRateLimitWait
+	}
+	return wait.Round(time.Second)
+}
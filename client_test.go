@@ -0,0 +1,48 @@
+// client_test.go
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+func TestRateLimitWait(t *testing.T) {
+	unix := func(d time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+	}
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		retry   int
+		want    time.Duration
+	}{
+		{name: "first retry", want: time.Second},
+		{name: "doubling", retry: 2, want: 4 * time.Second},
+		{name: "Retry-After", headers: map[string]string{"Retry-After": "30"}, retry: 2, want: 30 * time.Second},
+		{name: "invalid Retry-After", headers: map[string]string{"Retry-After": "soon"}, retry: 1, want: 2 * time.Second},
+		{name: "RateLimit-Reset", headers: map[string]string{"RateLimit-Reset": unix(10*time.Second + 500*time.Millisecond)}, want: 10 * time.Second},
+		{name: "RateLimit-Reset passed", headers: map[string]string{"RateLimit-Reset": unix(-time.Minute)}, retry: 1, want: 2 * time.Second},
+		{name: "long Retry-After", headers: map[string]string{"Retry-After": "3600"}, want: maxRateLimitWait},
+		{name: "distant RateLimit-Reset", headers: map[string]string{"RateLimit-Reset": unix(time.Hour)}, want: maxRateLimitWait},
+		{name: "Retry-After first", headers: map[string]string{"Retry-After": "5", "RateLimit-Reset": unix(time.Minute)}, want: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &gitlab.Response{Response: &http.Response{Header: http.Header{}}}
+			for key, value := range tt.headers {
+				resp.Header.Set(key, value)
+			}
+
+			if got := rateLimitWait(resp, tt.retry); got != tt.want {
+				t.Errorf("rateLimitWait() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
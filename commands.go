@@ -0,0 +1,63 @@
+// commands.go
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/rivo/tview"
+)
+
+// projectURL returns the web URL of the project of a job or pipeline, given
+// the web URL of that, e.g. "https://host/group/app" for
+// "https://host/group/app/-/jobs/42".
+func projectURL(webURL string) string {
+	if i := strings.Index(webURL, "/-/"); i >= 0 {
+		return webURL[:i]
+	}
+	return webURL
+}
+
+// apiCommand returns a curl command fetching path of the API of a project.
+// The token is left to the environment of whoever runs it, so the command
+// can be shared.
+func apiCommand(projectID, path string) string {
+	return fmt.Sprintf(`curl --header "PRIVATE-TOKEN: $GITLAB_PERSONAL_TOKEN" "%s/api/v4/projects/%s/%s"`,
+		gitlabURL, url.PathEscape(projectID), path)
+}
+
+// jobCommands returns the glab and curl commands printing the log of a job.
+func jobCommands(projectID string, jobID int, webURL string) (glab, curl string) {
+	glab = fmt.Sprintf("glab ci trace %d -R %s", jobID, projectURL(webURL))
+	curl = apiCommand(projectID, fmt.Sprintf("jobs/%d/trace", jobID))
+	return glab, curl
+}
+
+// pipelineCommands returns the glab and curl commands showing a pipeline.
+func pipelineCommands(projectID string, pipelineID int, webURL string) (glab, curl string) {
+	glab = fmt.Sprintf("glab ci get --pipeline-id %d -R %s", pipelineID, projectURL(webURL))
+	curl = apiCommand(projectID, fmt.Sprintf("pipelines/%d", pipelineID))
+	return glab, curl
+}
+
+// copyCommand asks whether to copy the glab or the curl command to the
+// clipboard, restoring returnTo afterwards. what names the object the
+// commands are about.
+func copyCommand(app *tview.Application, what, glab, curl string, returnTo tview.Primitive) {
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("Copy a command for %s:\n\n%s\n\n%s", what, tview.Escape(glab), tview.Escape(curl))).
+		AddButtons([]string{"glab", "curl", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case "glab":
+				copyToClipboard(app, glab, "the glab command", returnTo)
+			case "curl":
+				copyToClipboard(app, curl, "the curl command", returnTo)
+			default:
+				app.SetRoot(returnTo, true)
+			}
+		})
+
+	app.SetRoot(modal, false).SetFocus(modal)
+}
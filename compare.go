@@ -0,0 +1,185 @@
+// compare.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// compareKeys are the shortcuts of the pipeline comparison.
+var compareKeys = []keyHelp{
+	{"Esc", "Back"},
+}
+
+// jobComparison is a job of two compared pipelines, matched by name. before
+// or after is nil for jobs only one of the pipelines has.
+type jobComparison struct {
+	name   string
+	before *gitlab.Job
+	after  *gitlab.Job
+}
+
+// The kinds of job comparisons, in the order they're listed.
+const (
+	newlyFailing = iota
+	newlyPassing
+	statusChanged
+	unchanged
+)
+
+// comparisonHeadings are the headings of the kinds of job comparisons.
+var comparisonHeadings = []string{"Newly failing", "Newly passing", "Status changed", "Unchanged"}
+
+// fetchAndShowComparison compares the jobs of two pipelines of a project,
+// before being the older one. crumbs is the breadcrumb of the pipeline list.
+func fetchAndShowComparison(app *tview.Application, projectID string, before, after *gitlab.PipelineInfo, crumbs []string, returnTo tview.Primitive) {
+	var beforeJobs, afterJobs []*gitlab.Job
+	loadAsync(app, "Loading jobs...", returnTo, func(ctx context.Context) (err error) {
+		beforeJobs, err = listPipelineJobs(ctx, gitlabClient, projectID, strconv.Itoa(before.ID))
+		if err != nil {
+			return err
+		}
+		afterJobs, err = listPipelineJobs(ctx, gitlabClient, projectID, strconv.Itoa(after.ID))
+		return err
+	}, func(err error) {
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
+
+		comparisons := compareJobs(beforeJobs, afterJobs)
+		showComparison(app, before.ID, after.ID, comparisons, withCrumb(crumbs, fmt.Sprintf("#%d vs #%d", before.ID, after.ID)))
+	})
+}
+
+// compareJobs matches the jobs of two pipelines by name, in the order of
+// after followed by the jobs only before has.
+func compareJobs(before, after []*gitlab.Job) []jobComparison {
+	beforeByName := make(map[string]*gitlab.Job)
+	for _, job := range before {
+		beforeByName[job.Name] = job
+	}
+
+	var comparisons []jobComparison
+	seen := make(map[string]bool)
+	for _, job := range after {
+		comparisons = append(comparisons, jobComparison{name: job.Name, before: beforeByName[job.Name], after: job})
+		seen[job.Name] = true
+	}
+	for _, job := range before {
+		if !seen[job.Name] {
+			comparisons = append(comparisons, jobComparison{name: job.Name, before: job})
+		}
+	}
+	return comparisons
+}
+
+// isFailing reports whether job failed without being allowed to. A missing
+// job isn't failing.
+func isFailing(job *gitlab.Job) bool {
+	return job != nil && job.Status == "failed" && !job.AllowFailure
+}
+
+// comparisonKind tells how a job changed from one pipeline to the other.
+func comparisonKind(c jobComparison) int {
+	switch {
+	case isFailing(c.after) && !isFailing(c.before):
+		return newlyFailing
+	case isFailing(c.before) && c.after != nil && c.after.Status == "success":
+		return newlyPassing
+	case jobStatus(c.before) != jobStatus(c.after):
+		return statusChanged
+	}
+	return unchanged
+}
+
+// jobStatus returns the status of job, or "-" for a missing job.
+func jobStatus(job *gitlab.Job) string {
+	if job == nil {
+		return "-"
+	}
+	return job.Status
+}
+
+// durationDelta returns the durations of a job in both pipelines and how much
+// longer or shorter it took the second time, if it ran both times.
+func durationDelta(c jobComparison) string {
+	if c.before == nil || c.after == nil || c.before.Duration == 0 || c.after.Duration == 0 {
+		return ""
+	}
+
+	text := humanizeDuration(c.before.Duration) + " -> " + humanizeDuration(c.after.Duration)
+	delta := c.after.Duration - c.before.Duration
+	switch {
+	case delta >= 1:
+		text += fmt.Sprintf(" %s(+%s)[-]", statusColor("failed"), humanizeDuration(delta))
+	case delta <= -1:
+		text += fmt.Sprintf(" %s(-%s)[-]", statusColor("success"), humanizeDuration(-delta))
+	}
+	return text
+}
+
+// showComparison lists the compared jobs grouped by how they changed, each
+// with its status in both pipelines and its durations.
+func showComparison(app *tview.Application, beforeID, afterID int, comparisons []jobComparison, crumbs []string) {
+	byKind := make([][]jobComparison, len(comparisonHeadings))
+	width := 0
+	for _, c := range comparisons {
+		kind := comparisonKind(c)
+		byKind[kind] = append(byKind[kind], c)
+		if len(c.name) > width {
+			width = len(c.name)
+		}
+	}
+
+	var text strings.Builder
+	for kind, kindComparisons := range byKind {
+		if len(kindComparisons) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&text, "%s%s (%d)[-::-]\n", boldColorTag(theme.Accent), comparisonHeadings[kind], len(kindComparisons))
+		for _, c := range kindComparisons {
+			before, after := jobStatus(c.before), jobStatus(c.after)
+			fmt.Fprintf(&text, "  %-*s  %s%-8s[-] -> %s%-8s[-]  %s\n",
+				width, tview.Escape(c.name), statusColor(before), before, statusColor(after), after, durationDelta(c))
+		}
+		text.WriteString("\n")
+	}
+
+	comparisonView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(text.String())
+	comparisonView.SetBorder(true).SetTitle(fmt.Sprintf(" Pipeline #%d vs #%d - %d newly failing, %d newly passing ",
+		beforeID, afterID, len(byKind[newlyFailing]), len(byKind[newlyPassing])))
+
+	flex := tview.NewFlex()
+
+	comparisonView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			popView(app)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, compareKeys, flex)
+			return nil
+		}
+		return event
+	})
+
+	flex.SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, crumbs), 1, 0, false).
+		AddItem(comparisonView, 0, 1, true).
+		AddItem(buildFooter(app, func() {
+			popView(app)
+		}), 1, 0, false)
+
+	pushView(app, flex)
+}
@@ -0,0 +1,86 @@
+// compare_test.go
+package main
+
+import (
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// namedJob returns a job called name with status.
+func namedJob(name, status string) *gitlab.Job {
+	return &gitlab.Job{Name: name, Status: status}
+}
+
+func TestCompareJobs(t *testing.T) {
+	allowed := namedJob("lint", "failed")
+	allowed.AllowFailure = true
+
+	tests := []struct {
+		name      string
+		before    []*gitlab.Job
+		after     []*gitlab.Job
+		wantNames []string
+		wantKinds []int
+	}{
+		{
+			name:      "unchanged",
+			before:    []*gitlab.Job{namedJob("build", "success")},
+			after:     []*gitlab.Job{namedJob("build", "success")},
+			wantNames: []string{"build"},
+			wantKinds: []int{unchanged},
+		},
+		{
+			name:      "newly failing and passing",
+			before:    []*gitlab.Job{namedJob("build", "success"), namedJob("test", "failed")},
+			after:     []*gitlab.Job{namedJob("build", "failed"), namedJob("test", "success")},
+			wantNames: []string{"build", "test"},
+			wantKinds: []int{newlyFailing, newlyPassing},
+		},
+		{
+			name:      "allowed failure isn't failing",
+			before:    []*gitlab.Job{namedJob("lint", "success")},
+			after:     []*gitlab.Job{allowed},
+			wantNames: []string{"lint"},
+			wantKinds: []int{statusChanged},
+		},
+		{
+			name:      "status changed",
+			before:    []*gitlab.Job{namedJob("deploy", "manual")},
+			after:     []*gitlab.Job{namedJob("deploy", "skipped")},
+			wantNames: []string{"deploy"},
+			wantKinds: []int{statusChanged},
+		},
+		{
+			name:      "new job failing",
+			after:     []*gitlab.Job{namedJob("e2e", "failed")},
+			wantNames: []string{"e2e"},
+			wantKinds: []int{newlyFailing},
+		},
+		{
+			name:      "removed job comes last",
+			before:    []*gitlab.Job{namedJob("old", "failed"), namedJob("build", "success")},
+			after:     []*gitlab.Job{namedJob("build", "success")},
+			wantNames: []string{"build", "old"},
+			wantKinds: []int{unchanged, statusChanged},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			comparisons := compareJobs(tt.before, tt.after)
+			if len(comparisons) != len(tt.wantNames) {
+				t.Fatalf("compareJobs() returned %d comparisons, want %d", len(comparisons), len(tt.wantNames))
+			}
+			for i, c := range comparisons {
+				if c.name != tt.wantNames[i] {
+					t.Errorf("comparison %d is of %q, want %q", i, c.name, tt.wantNames[i])
+				}
+				if kind := comparisonKind(c); kind != tt.wantKinds[i] {
+					t.Errorf("kind of %q = %s, want %s", c.name, comparisonHeadings[kind], comparisonHeadings[tt.wantKinds[i]])
+				}
+			}
+		})
+	}
+}
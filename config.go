@@ -0,0 +1,253 @@
+// config.go
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultGitLabURL = "https://gitlab.com"
+
+// Config holds the settings read from the config file. Environment variables
+// take precedence over the top-level token and url.
+type Config struct {
+	Token    string    `yaml:"token"`
+	URL      string    `yaml:"url"`
+	Profiles []Profile `yaml:"profiles"`
+	Theme    Theme     `yaml:"theme"`
+
+	// CACertFile and InsecureSkipVerify are the TLS settings of the
+	// default profile, see Profile.
+	CACertFile         string `yaml:"ca_cert_file"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+
+	// CacheTTL is how long list results are reused. Unset means
+	// defaultCacheTTL, 0 turns caching off.
+	CacheTTL *time.Duration `yaml:"cache_ttl"`
+
+	// LogLimitKB is how much of the end of a job log is shown. Unset means
+	// defaultLogLimitKB, 0 shows logs in full.
+	LogLimitKB *int `yaml:"log_limit_kb"`
+
+	// ConfirmActions asks before retrying, canceling or running pipelines
+	// and jobs. Unset means true.
+	ConfirmActions *bool `yaml:"confirm_actions"`
+
+	// PerPage is the page size used to fetch whole lists, 1 to 100.
+	PerPage *int `yaml:"per_page"`
+
+	// MaxConcurrency bounds how many requests background fetches run at
+	// once, 1 to maxConcurrencyLimit.
+	MaxConcurrency *int `yaml:"max_concurrency"`
+
+	// AutoRefresh is how often pipeline lists refresh themselves, e.g.
+	// "30s". Unset or 0 leaves it to R.
+	AutoRefresh *time.Duration `yaml:"auto_refresh"`
+
+	// Timeout is how long loading anything may take before it's given up
+	// on, e.g. "2m". Unset means defaultLoadTimeout, 0 waits forever.
+	Timeout *time.Duration `yaml:"timeout"`
+
+	// MembershipOnly starts the group tree with only the projects the user
+	// is a member of, by namespace, instead of all groups.
+	MembershipOnly bool `yaml:"membership_only"`
+
+	// ShowArchived shows archived projects in the group tree, which hides
+	// them by default.
+	ShowArchived bool `yaml:"show_archived"`
+
+	// ActivityDays is how recently projects must have been active to be
+	// shown while inactive projects are hidden in the tree. Unset means
+	// defaultActivityDays.
+	ActivityDays *int `yaml:"activity_days"`
+
+	// PipelineColumns are the fields shown of every pipeline in pipeline
+	// lists, in order. Unset means defaultPipelineColumns.
+	PipelineColumns []string `yaml:"pipeline_columns"`
+
+	// Startup is what's shown first after connecting: "menu", the default,
+	// "groups" for the group tree, "search" for the group search or "last"
+	// to resume where the user left off.
+	Startup string `yaml:"startup"`
+
+	// Bell rings the terminal bell when a watched pipeline finishes.
+	Bell bool `yaml:"bell"`
+}
+
+// Profile is a named GitLab instance together with the token used for it.
+type Profile struct {
+	Name  string `yaml:"name"`
+	URL   string `yaml:"url"`
+	Token string `yaml:"token"`
+
+	// CACertFile is a PEM file with the certificate of the CA that signed
+	// the certificate of a self-hosted instance, trusted in addition to the
+	// system's CAs.
+	CACertFile string `yaml:"ca_cert_file"`
+
+	// InsecureSkipVerify turns off checking the certificate of the
+	// instance altogether. Only meant for testing.
+	InsecureSkipVerify bool `yaml:"insecure_skip_verify"`
+}
+
+// allProfiles returns the configured profiles, preceded by a "default" profile
+// built from the environment and top-level keys when a token is available
+// there. The URLs of the profiles are normalized; it fails on malformed ones.
+func (c *Config) allProfiles() ([]Profile, error) {
+	var profiles []Profile
+
+	token := os.Getenv("GITLAB_PERSONAL_TOKEN")
+	if token == "" {
+		token = c.Token
+	}
+
+	baseURL := os.Getenv("GITLAB_URL")
+	if baseURL == "" {
+		baseURL = c.URL
+	}
+	if baseURL == "" {
+		baseURL = defaultGitLabURL
+	}
+
+	if token != "" {
+		profiles = append(profiles, Profile{
+			Name:               "default",
+			URL:                baseURL,
+			Token:              token,
+			CACertFile:         c.CACertFile,
+			InsecureSkipVerify: c.InsecureSkipVerify,
+		})
+	}
+
+	for _, profile := range c.Profiles {
+		if profile.URL == "" {
+			profile.URL = defaultGitLabURL
+		}
+		profiles = append(profiles, profile)
+	}
+
+	for i := range profiles {
+		normalized, err := normalizeURL(profiles[i].URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL of profile %s: %w", profiles[i].Name, err)
+		}
+		profiles[i].URL = normalized
+	}
+
+	return profiles, nil
+}
+
+// normalizeURL returns the URL of an instance without trailing slashes or
+// API path, e.g. "https://host/gitlab" for "https://host/gitlab/api/v4/",
+// so "/api/v4" and web paths can be appended to it. Instances may live
+// below a path.
+func normalizeURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%q must start with http:// or https://", rawURL)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("%q has no host", rawURL)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("%q must not have a query or fragment", rawURL)
+	}
+
+	u.Path = strings.TrimSuffix(strings.TrimRight(u.Path, "/"), "/api/v4")
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// validate checks the settings that have a limited range.
+func (c *Config) validate() error {
+	if c.PerPage != nil && (*c.PerPage < 1 || *c.PerPage > 100) {
+		return fmt.Errorf("per_page must be between 1 and 100, got %d", *c.PerPage)
+	}
+	if c.MaxConcurrency != nil && (*c.MaxConcurrency < 1 || *c.MaxConcurrency > maxConcurrencyLimit) {
+		return fmt.Errorf("max_concurrency must be between 1 and %d, got %d", maxConcurrencyLimit, *c.MaxConcurrency)
+	}
+	if c.AutoRefresh != nil && *c.AutoRefresh != 0 && *c.AutoRefresh < time.Second {
+		return fmt.Errorf("auto_refresh must be 0 or at least 1s, got %s", *c.AutoRefresh)
+	}
+	if c.Timeout != nil && *c.Timeout != 0 && *c.Timeout < time.Second {
+		return fmt.Errorf("timeout must be 0 or at least 1s, got %s", *c.Timeout)
+	}
+	if c.ActivityDays != nil && *c.ActivityDays < 1 {
+		return fmt.Errorf("activity_days must be at least 1, got %d", *c.ActivityDays)
+	}
+	for _, column := range c.PipelineColumns {
+		if _, ok := pipelineColumnLabels[column]; !ok {
+			return fmt.Errorf("unknown pipeline column %q, must be one of id, status, ref, source, user, jobs, updated, duration or sha", column)
+		}
+	}
+	switch c.Startup {
+	case "", "menu", "groups", "search", "last":
+	default:
+		return fmt.Errorf("startup must be menu, groups, search or last, got %q", c.Startup)
+	}
+	if c.LogLimitKB != nil && *c.LogLimitKB < 0 {
+		return fmt.Errorf("log_limit_kb must not be negative, got %d", *c.LogLimitKB)
+	}
+	return nil
+}
+
+func findProfile(profiles []Profile, name string) (Profile, bool) {
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+func defaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gitlab-pipe-viewer", "config.yaml")
+}
+
+// loadConfig reads the config file at path, or the default location when path
+// is empty. A missing default config file is not an error.
+func loadConfig(path string) (*Config, error) {
+	cfg := &Config{}
+
+	explicit := path != ""
+	if !explicit {
+		path = defaultConfigPath()
+		if path == "" {
+			return cfg, nil
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("reading config %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
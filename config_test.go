@@ -0,0 +1,81 @@
+// config_test.go
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawURL  string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", rawURL: "https://gitlab.com", want: "https://gitlab.com"},
+		{name: "trailing slashes", rawURL: "https://gitlab.com//", want: "https://gitlab.com"},
+		{name: "API path", rawURL: "https://host/api/v4/", want: "https://host"},
+		{name: "below a path", rawURL: "https://host/gitlab/api/v4", want: "https://host/gitlab"},
+		{name: "surrounding spaces", rawURL: "  http://host:8080/ ", want: "http://host:8080"},
+		{name: "no scheme", rawURL: "gitlab.com", wantErr: true},
+		{name: "other scheme", rawURL: "ftp://gitlab.com", wantErr: true},
+		{name: "no host", rawURL: "https:///gitlab", wantErr: true},
+		{name: "query", rawURL: "https://gitlab.com/?a=b", wantErr: true},
+		{name: "fragment", rawURL: "https://gitlab.com/#top", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeURL(tt.rawURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeURL(%q) error = %v, want error %v", tt.rawURL, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeURL(%q) = %q, want %q", tt.rawURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	durationPtr := func(d time.Duration) *time.Duration { return &d }
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{name: "empty"},
+		{name: "all valid", config: Config{
+			PerPage:         intPtr(100),
+			MaxConcurrency:  intPtr(1),
+			AutoRefresh:     durationPtr(0),
+			Timeout:         durationPtr(time.Second),
+			ActivityDays:    intPtr(1),
+			Startup:         "last",
+			LogLimitKB:      intPtr(0),
+			PipelineColumns: []string{"id", "duration", "sha"},
+		}},
+		{name: "per_page too small", config: Config{PerPage: intPtr(0)}, wantErr: true},
+		{name: "per_page too large", config: Config{PerPage: intPtr(101)}, wantErr: true},
+		{name: "max_concurrency too large", config: Config{MaxConcurrency: intPtr(maxConcurrencyLimit + 1)}, wantErr: true},
+		{name: "auto_refresh too short", config: Config{AutoRefresh: durationPtr(time.Millisecond)}, wantErr: true},
+		{name: "timeout too short", config: Config{Timeout: durationPtr(time.Millisecond)}, wantErr: true},
+		{name: "activity_days zero", config: Config{ActivityDays: intPtr(0)}, wantErr: true},
+		{name: "unknown startup", config: Config{Startup: "tree"}, wantErr: true},
+		{name: "negative log_limit_kb", config: Config{LogLimitKB: intPtr(-1)}, wantErr: true},
+		{name: "unknown pipeline column", config: Config{PipelineColumns: []string{"id", "author"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.validate(); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+// dashboard.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+const (
+	// dashboardProjects is how many of the user's most recently active
+	// projects the dashboard looks at.
+	dashboardProjects = 50
+
+	// dashboardProjectPipelines is how many pipelines are fetched per
+	// project, and dashboardPipelines how many are shown in total.
+	dashboardProjectPipelines = 5
+	dashboardPipelines        = 50
+)
+
+// dashboardKeys are the shortcuts of the dashboard.
+var dashboardKeys = []keyHelp{
+	{"Enter", "Show stages"},
+	{"r", "Refresh"},
+	{"Esc", "Back"},
+}
+
+// projectPipeline is a pipeline together with the project it belongs to.
+type projectPipeline struct {
+	project  *gitlab.Project
+	pipeline *gitlab.PipelineInfo
+}
+
+// showDashboard lists the most recently updated pipelines across the
+// projects the user is a member of. Canceling the load restores returnTo.
+func showDashboard(app *tview.Application, returnTo tview.Primitive) {
+	pipelineList := tview.NewList()
+	pipelineList.SetBorder(true)
+	flex := tview.NewFlex()
+
+	// emptyMessage takes the place of the list while it's empty. The list
+	// keeps the focus, so its keys still work.
+	emptyMessage := buildEmptyMessage("No pipelines in your projects")
+	emptyMessage.SetBorder(true)
+
+	crumbs := []string{instanceCrumb(), "recent pipelines"}
+
+	// loads counts the loads, so stage statuses still arriving for an
+	// earlier one are dropped.
+	loads := 0
+
+	// setPipelines lists pipelines. limited tells they're only those of the
+	// dashboardProjects most recently active projects of the user.
+	setPipelines := func(pipelines []projectPipeline, limited bool) {
+		loads++
+		load := loads
+		pipelineList.Clear()
+		projects := "your projects"
+		if limited {
+			projects = fmt.Sprintf("your %d most recently active projects", dashboardProjects)
+		}
+		pipelineList.SetTitle(fmt.Sprintf(" Recent pipelines of %s (%d) ", projects, len(pipelines)))
+		emptyMessage.SetTitle(pipelineList.GetTitle())
+		for _, p := range pipelines {
+			p := p
+			pipelineList.AddItem(dashboardInfo(p, nil), "", 0, func() {
+				fetchAndShowStages(app, strconv.Itoa(p.project.ID), strconv.Itoa(p.pipeline.ID), p.pipeline.Ref,
+					[]string{instanceCrumb(), p.project.PathWithNamespace}, flex)
+			})
+		}
+
+		if len(pipelines) == 0 {
+			flex.ResizeItem(pipelineList, 0, 0).ResizeItem(emptyMessage, 0, 1)
+		} else {
+			flex.ResizeItem(pipelineList, 0, 1).ResizeItem(emptyMessage, 0, 0)
+		}
+
+		// The stage bars are filled in as they arrive.
+		go fetchStageStatuses(pipelines, func(index int, statuses []string) {
+			app.QueueUpdateDraw(func() {
+				if load == loads {
+					pipelineList.SetItemText(index, dashboardInfo(pipelines[index], statuses), "")
+				}
+			})
+		})
+	}
+
+	load := func(returnTo tview.Primitive, done func()) {
+		var pipelines []projectPipeline
+		var limited bool
+		loadAsync(app, "Loading projects...", returnTo, func(ctx context.Context) (err error) {
+			pipelines, limited, err = recentPipelines(ctx, gitlabClient)
+			return err
+		}, func(err error) {
+			if err != nil {
+				showError(app, err.Error(), returnTo)
+				return
+			}
+
+			setPipelines(pipelines, limited)
+			pushView(app, flex)
+			if done != nil {
+				done()
+			}
+		})
+	}
+
+	pipelineList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			popView(app)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, dashboardKeys, flex)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'r':
+			clearCache()
+			index := pipelineList.GetCurrentItem()
+			load(flex, func() {
+				pipelineList.SetCurrentItem(index)
+			})
+			return nil
+		}
+		return event
+	})
+
+	flex.SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, crumbs), 1, 0, false).
+		AddItem(pipelineList, 0, 1, true).
+		AddItem(emptyMessage, 0, 0, false).
+		AddItem(buildFooter(app, func() {
+			popView(app)
+		}), 1, 0, false)
+
+	load(returnTo, nil)
+}
+
+// recentPipelines returns the most recently updated pipelines of the
+// projects the user is a member of, newest first. The pipelines of the
+// projects are fetched by maxConcurrency workers; projects whose pipelines
+// can't be fetched, e.g. because CI/CD is disabled for them, are left out.
+// Only the dashboardProjects most recently active projects are looked at;
+// limited tells the user has more.
+func recentPipelines(ctx context.Context, gl GitLab) (pipelines []projectPipeline, limited bool, err error) {
+	projects, resp, err := gl.ListProjects(ctx, &gitlab.ListProjectsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: dashboardProjects},
+		Membership:  gitlab.Bool(true),
+		Archived:    gitlab.Bool(false),
+		OrderBy:     gitlab.String("last_activity_at"),
+		Sort:        gitlab.String("desc"),
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("Error fetching your projects: %w", err)
+	}
+	limited = resp.NextPage != 0
+
+	var (
+		mu      sync.Mutex
+		fetched int
+	)
+
+	todo := make(chan *gitlab.Project)
+	var wg sync.WaitGroup
+	for i := 0; i < maxConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for project := range todo {
+				projectPipelines, _, err := gl.ListProjectPipelines(ctx, project.ID, &gitlab.ListProjectPipelinesOptions{
+					ListOptions: gitlab.ListOptions{PerPage: dashboardProjectPipelines},
+					OrderBy:     gitlab.String("updated_at"),
+					Sort:        gitlab.String("desc"),
+				})
+				// A project whose pipelines can't be fetched is left out of
+				// the dashboard, the error is only logged.
+				if err != nil && ctx.Err() == nil {
+					logError("Error fetching pipelines of project %s: %v", project.PathWithNamespace, err)
+				}
+
+				mu.Lock()
+				if err == nil {
+					for _, pipeline := range projectPipelines {
+						pipelines = append(pipelines, projectPipeline{project: project, pipeline: pipeline})
+					}
+				}
+				fetched++
+				reportStatus(ctx, fmt.Sprintf("Loading pipelines... %d/%d projects", fetched, len(projects)))
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, project := range projects {
+		if ctx.Err() != nil {
+			break
+		}
+		todo <- project
+	}
+	close(todo)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+
+	sort.SliceStable(pipelines, func(i, j int) bool {
+		a, b := pipelines[i].pipeline.UpdatedAt, pipelines[j].pipeline.UpdatedAt
+		return a != nil && (b == nil || a.After(*b))
+	})
+	if len(pipelines) > dashboardPipelines {
+		pipelines = pipelines[:dashboardPipelines]
+	}
+	return pipelines, limited, nil
+}
+
+// fetchStageStatuses fetches the statuses of the stages of pipelines with a
+// pool of maxConcurrency workers, calling found with the index of each
+// pipeline as they arrive. Pipelines whose jobs can't be fetched are
+// skipped.
+func fetchStageStatuses(pipelines []projectPipeline, found func(index int, statuses []string)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < maxConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				p := pipelines[index]
+				jobs, err := listPipelineJobs(context.Background(), gitlabClient, strconv.Itoa(p.project.ID), strconv.Itoa(p.pipeline.ID))
+				if err != nil {
+					logError("%v", err)
+					continue
+				}
+				found(index, stageStatuses(jobs))
+			}
+		}()
+	}
+
+	for index := range pipelines {
+		indexes <- index
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// dashboardInfo returns the text of a pipeline on the dashboard, with a bar
+// of the statuses of its stages once they're known.
+func dashboardInfo(p projectPipeline, stages []string) string {
+	updated := "-"
+	if p.pipeline.UpdatedAt != nil {
+		updated = p.pipeline.UpdatedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return fmt.Sprintf("%s%s[-] \nPipeline ID: %d \nStatus: %s%s[-] %s\nRef: %s \nUpdated At: %s \n",
+		colorTag(theme.Accent), tview.Escape(p.project.PathWithNamespace), p.pipeline.ID,
+		statusColor(p.pipeline.Status), p.pipeline.Status, stageBar(stages), tview.Escape(p.pipeline.Ref), updated)
+}
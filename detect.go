@@ -0,0 +1,79 @@
+// detect.go
+package main
+
+import (
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// detectProject returns the path of the project the origin remote of the git
+// repository in the working directory points to, the branch checked out
+// there and the profile of the instance the remote is on. ok is false if
+// there is no such remote or it's on none of the profiles' instances.
+func detectProject(profiles []Profile) (project, branch string, profile Profile, ok bool) {
+	out, err := exec.Command("git", "remote", "get-url", "origin").Output()
+	if err != nil {
+		return "", "", Profile{}, false
+	}
+
+	remote := strings.TrimSpace(string(out))
+	for _, p := range profiles {
+		if project, ok = remoteProjectPath(remote, p.URL); ok {
+			profile = p
+			break
+		}
+	}
+	if !ok {
+		return "", "", Profile{}, false
+	}
+
+	// A detached HEAD has no branch, which leaves the choice to the user.
+	out, err = exec.Command("git", "symbolic-ref", "--quiet", "--short", "HEAD").Output()
+	if err == nil {
+		branch = strings.TrimSpace(string(out))
+	}
+
+	return project, branch, profile, true
+}
+
+// remoteProjectPath returns the project path of a remote URL on the instance
+// at instanceURL, e.g. "mygroup/app" for "git@gitlab.com:mygroup/app.git" or
+// "https://gitlab.com/mygroup/app.git". Instances below a path, such as
+// "https://host/gitlab", have it in their HTTP remotes but not in their SSH
+// ones; it's stripped either way. ok is false for remotes on other hosts.
+func remoteProjectPath(remote, instanceURL string) (string, bool) {
+	instance, err := url.Parse(instanceURL)
+	if err != nil {
+		return "", false
+	}
+
+	var host, path string
+	if u, err := url.Parse(remote); err == nil && u.Scheme != "" && u.Host != "" {
+		host, path = u.Hostname(), u.Path
+	} else if before, after, found := strings.Cut(remote, ":"); found {
+		// scp-like syntax: [user@]host:path
+		_, host, _ = strings.Cut(before, "@")
+		if host == "" {
+			host = before
+		}
+		path = after
+	} else {
+		return "", false
+	}
+
+	if !strings.EqualFold(host, instance.Hostname()) {
+		return "", false
+	}
+
+	path = "/" + strings.Trim(path, "/")
+	if prefix := strings.TrimRight(instance.Path, "/"); prefix != "" && strings.HasPrefix(path, prefix+"/") {
+		path = strings.TrimPrefix(path, prefix)
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if !strings.Contains(path, "/") {
+		return "", false
+	}
+	return path, true
+}
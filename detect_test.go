@@ -0,0 +1,35 @@
+// detect_test.go
+package main
+
+import "testing"
+
+func TestRemoteProjectPath(t *testing.T) {
+	tests := []struct {
+		name        string
+		remote      string
+		instanceURL string
+		want        string
+		wantOK      bool
+	}{
+		{name: "SSH", remote: "git@gitlab.com:mygroup/app.git", instanceURL: "https://gitlab.com", want: "mygroup/app", wantOK: true},
+		{name: "HTTPS", remote: "https://gitlab.com/mygroup/app.git", instanceURL: "https://gitlab.com", want: "mygroup/app", wantOK: true},
+		{name: "subgroups", remote: "https://gitlab.com/mygroup/sub/app", instanceURL: "https://gitlab.com", want: "mygroup/sub/app", wantOK: true},
+		{name: "SSH URL with port", remote: "ssh://git@host:2222/mygroup/app.git", instanceURL: "https://host", want: "mygroup/app", wantOK: true},
+		{name: "HTTPS below a path", remote: "https://host/gitlab/mygroup/app.git", instanceURL: "https://host/gitlab", want: "mygroup/app", wantOK: true},
+		{name: "SSH below a path", remote: "git@host:mygroup/app.git", instanceURL: "https://host/gitlab", want: "mygroup/app", wantOK: true},
+		{name: "host case", remote: "git@GitLab.com:mygroup/app.git", instanceURL: "https://gitlab.com", want: "mygroup/app", wantOK: true},
+		{name: "other host", remote: "git@github.com:mygroup/app.git", instanceURL: "https://gitlab.com"},
+		{name: "no namespace", remote: "https://gitlab.com/app.git", instanceURL: "https://gitlab.com"},
+		{name: "local path", remote: "/srv/git/app.git", instanceURL: "https://gitlab.com"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := remoteProjectPath(tt.remote, tt.instanceURL)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("remoteProjectPath(%q, %q) = %q, %v, want %q, %v", tt.remote, tt.instanceURL, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// environments.go
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// environmentKeys are the shortcuts of the environment list.
+var environmentKeys = []keyHelp{
+	{"Enter", "Show deployments"},
+	{"Esc", "Back"},
+}
+
+// deploymentKeys are the shortcuts of the deployment list.
+var deploymentKeys = []keyHelp{
+	{"Enter", "Show the deployment's pipeline"},
+	{"Esc", "Back"},
+}
+
+// fetchAndShowEnvironments lists the environments of a project. crumbs is
+// the breadcrumb of the project.
+func fetchAndShowEnvironments(app *tview.Application, projectID string, crumbs []string, returnTo tview.Primitive) {
+	var environments []*gitlab.Environment
+	loadAsync(app, "Loading environments...", returnTo, func(ctx context.Context) (err error) {
+		environments, err = listEnvironments(ctx, gitlabClient, projectID)
+		return err
+	}, func(err error) {
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
+
+		if len(environments) == 0 {
+			showMessage(app, "No environments found.", returnTo)
+			return
+		}
+
+		showEnvironmentList(app, projectID, environments, withCrumb(crumbs, "environments"))
+	})
+}
+
+func listEnvironments(ctx context.Context, gl GitLab, projectID string) ([]*gitlab.Environment, error) {
+	var environments []*gitlab.Environment
+	listOptions := &gitlab.ListEnvironmentsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: perPage,
+			Page:    1,
+		},
+	}
+
+	for {
+		page, resp, err := gl.ListEnvironments(ctx, projectID, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching environments for project %s: %w", projectID, err)
+		}
+
+		environments = append(environments, page...)
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+
+	return environments, nil
+}
+
+// showEnvironmentList lists environments with their last deployment.
+func showEnvironmentList(app *tview.Application, projectID string, environments []*gitlab.Environment, crumbs []string) {
+	environmentList := tview.NewList()
+	flex := tview.NewFlex()
+
+	// The background fetches stop with ctx once the list is left.
+	ctx, cancel := context.WithCancel(context.Background())
+	back := func() {
+		cancel()
+		popView(app)
+	}
+
+	for i, environment := range environments {
+		environment := environment
+		environmentList.AddItem(environmentInfo(environment), "", 0, func() {
+			fetchAndShowDeployments(app, projectID, environment.Name, withCrumb(crumbs, environment.Name), flex)
+		})
+
+		// Newer GitLab versions leave the last deployment out of the list,
+		// so it's fetched in the background where it's missing.
+		if environment.LastDeployment == nil {
+			i := i
+			go func() {
+				// The last deployment is only extra information, so errors
+				// are ignored.
+				deployments, _, err := gitlabClient.ListProjectDeployments(ctx, projectID, &gitlab.ListProjectDeploymentsOptions{
+					ListOptions: gitlab.ListOptions{PerPage: 1},
+					OrderBy:     gitlab.String("id"),
+					Sort:        gitlab.String("desc"),
+					Environment: &environment.Name,
+				})
+				if err != nil || len(deployments) == 0 {
+					return
+				}
+				app.QueueUpdateDraw(func() {
+					if !isOpen(flex) {
+						cancel()
+						return
+					}
+					environment.LastDeployment = deployments[0]
+					environmentList.SetItemText(i, environmentInfo(environment), "")
+				})
+			}()
+		}
+	}
+
+	environmentList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			back()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, environmentKeys, flex)
+			return nil
+		}
+		return event
+	})
+
+	flex.SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, crumbs), 1, 0, false).
+		AddItem(environmentList, 0, 1, true).
+		AddItem(buildFooter(app, back), 1, 0, false)
+
+	pushView(app, flex)
+}
+
+// environmentInfo returns the text of an environment in the environment
+// list.
+func environmentInfo(environment *gitlab.Environment) string {
+	last := "none"
+	if deployment := environment.LastDeployment; deployment != nil {
+		last = fmt.Sprintf("#%d %s%s[-] of %s", deployment.IID, statusColor(deployment.Status), deployment.Status, tview.Escape(deployment.Ref))
+		if deployment.CreatedAt != nil {
+			last += " at " + deployment.CreatedAt.Format("2006-01-02 15:04:05")
+		}
+	}
+
+	return fmt.Sprintf("Environment: %s \nState: %s \nLast deployment: %s \n", tview.Escape(environment.Name), environment.State, last)
+}
+
+// fetchAndShowDeployments lists the recent deployments to an environment.
+// crumbs is the breadcrumb of the environment.
+func fetchAndShowDeployments(app *tview.Application, projectID, environment string, crumbs []string, returnTo tview.Primitive) {
+	var deployments []*gitlab.Deployment
+	loadAsync(app, "Loading deployments...", returnTo, func(ctx context.Context) (err error) {
+		deployments, _, err = gitlabClient.ListProjectDeployments(ctx, projectID, &gitlab.ListProjectDeploymentsOptions{
+			ListOptions: gitlab.ListOptions{PerPage: 20},
+			OrderBy:     gitlab.String("id"),
+			Sort:        gitlab.String("desc"),
+			Environment: &environment,
+		})
+		if err != nil {
+			return fmt.Errorf("Error fetching deployments to %s: %w", environment, err)
+		}
+		return nil
+	}, func(err error) {
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
+
+		if len(deployments) == 0 {
+			showMessage(app, "No deployments to "+environment+".", returnTo)
+			return
+		}
+
+		showDeploymentList(app, projectID, deployments, crumbs)
+	})
+}
+
+// showDeploymentList lists deployments. Selecting one shows the pipeline it
+// ran in.
+func showDeploymentList(app *tview.Application, projectID string, deployments []*gitlab.Deployment, crumbs []string) {
+	deploymentList := tview.NewList()
+	flex := tview.NewFlex()
+
+	for _, deployment := range deployments {
+		deployment := deployment
+		deploymentList.AddItem(deploymentInfo(deployment), "", 0, func() {
+			pipelineID := deployment.Deployable.Pipeline.ID
+			if pipelineID == 0 {
+				showMessage(app, "This deployment has no pipeline.", flex)
+				return
+			}
+			fetchAndShowStages(app, projectID, fmt.Sprintf("%d", pipelineID), deployment.Ref, crumbs, flex)
+		})
+	}
+
+	deploymentList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			popView(app)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, deploymentKeys, flex)
+			return nil
+		}
+		return event
+	})
+
+	flex.SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, crumbs), 1, 0, false).
+		AddItem(deploymentList, 0, 1, true).
+		AddItem(buildFooter(app, func() {
+			popView(app)
+		}), 1, 0, false)
+
+	pushView(app, flex)
+}
+
+// deploymentInfo returns the text of a deployment in the deployment list.
+func deploymentInfo(deployment *gitlab.Deployment) string {
+	user := "-"
+	if deployment.User != nil {
+		user = deployment.User.Username
+	}
+	created := "-"
+	if deployment.CreatedAt != nil {
+		created = deployment.CreatedAt.Format("2006-01-02 15:04:05")
+	}
+
+	return fmt.Sprintf("Deployment #%d \nStatus: %s%s[-] \nRef: %s (%.8s) \nJob: %s \nBy: %s at %s \n",
+		deployment.IID, statusColor(deployment.Status), deployment.Status, tview.Escape(deployment.Ref), deployment.SHA,
+		tview.Escape(deployment.Deployable.Name), tview.Escape(user), created)
+}
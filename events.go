@@ -0,0 +1,156 @@
+// events.go
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxEvents is how many events the event log keeps; older ones are dropped.
+const maxEvents = 500
+
+// eventLogHeight is the height of the event log panel, border included.
+const eventLogHeight = 12
+
+// loggedEvent is an entry of the event log.
+type loggedEvent struct {
+	at     time.Time
+	text   string
+	failed bool
+}
+
+// events is the log of errors and notable events of the session, oldest
+// first. Background goroutines log too, so it's guarded by eventsMu, as is
+// eventsChanged, which is called after every new event while the event log
+// panel is shown.
+var (
+	eventsMu      sync.Mutex
+	events        []loggedEvent
+	eventsChanged func()
+)
+
+// eventLogView is the event log panel while it's shown. It's drawn over the
+// bottom of whatever is on screen, so views and modals stay as they are.
+var eventLogView *tview.TextView
+
+// logEvent records a notable event, such as an action that was run. It's
+// safe to call from any goroutine.
+func logEvent(format string, args ...interface{}) {
+	addEvent(loggedEvent{at: time.Now(), text: fmt.Sprintf(format, args...)})
+}
+
+// logError records an error, including ones that are only retried in the
+// background and never shown otherwise. It's safe to call from any
+// goroutine.
+func logError(format string, args ...interface{}) {
+	addEvent(loggedEvent{at: time.Now(), text: fmt.Sprintf(format, args...), failed: true})
+}
+
+func addEvent(event loggedEvent) {
+	eventsMu.Lock()
+	events = append(events, event)
+	if len(events) > maxEvents {
+		events = events[len(events)-maxEvents:]
+	}
+	changed := eventsChanged
+	eventsMu.Unlock()
+
+	if changed != nil {
+		changed()
+	}
+}
+
+// eventLogText returns the events as shown in the event log panel, errors
+// in the failed color.
+func eventLogText() string {
+	eventsMu.Lock()
+	defer eventsMu.Unlock()
+
+	if len(events) == 0 {
+		return "Nothing happened yet."
+	}
+
+	var text strings.Builder
+	for _, event := range events {
+		line := event.at.Format("15:04:05") + " " + tview.Escape(event.text)
+		if event.failed {
+			line = statusColor("failed") + line + "[-]"
+		}
+		text.WriteString(line + "\n")
+	}
+	return text.String()
+}
+
+// toggleEventLog shows the event log in a panel over the bottom of the
+// screen, or closes it again. The panel follows new events as they are
+// logged.
+func toggleEventLog(app *tview.Application) {
+	if eventLogView != nil {
+		closeEventLog(app)
+		return
+	}
+
+	logView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true)
+	logView.SetBorder(true).SetTitle(" Event log - Ctrl-L or Esc to close ")
+
+	render := func() {
+		logView.SetText(eventLogText()).ScrollToEnd()
+	}
+	render()
+
+	eventLogView = logView
+	eventsMu.Lock()
+	eventsChanged = func() {
+		// Events are also logged on the UI goroutine, which must not wait
+		// for its own queue.
+		go app.QueueUpdateDraw(func() {
+			if eventLogView == logView {
+				render()
+			}
+		})
+	}
+	eventsMu.Unlock()
+
+	app.SetAfterDrawFunc(func(screen tcell.Screen) {
+		width, height := screen.Size()
+		logView.SetRect(0, height-eventLogHeight, width, eventLogHeight)
+		logView.Draw(screen)
+	})
+}
+
+// closeEventLog closes the event log panel, uncovering the screen below.
+func closeEventLog(app *tview.Application) {
+	eventsMu.Lock()
+	eventsChanged = nil
+	eventsMu.Unlock()
+
+	eventLogView = nil
+	app.SetAfterDrawFunc(nil)
+}
+
+// eventLogInput hands a key to the event log panel while it's shown, for
+// scrolling it, and reports whether it took the key. Esc closes the panel;
+// the global keys for quitting and toggling it are left alone.
+func eventLogInput(app *tview.Application, event *tcell.EventKey) bool {
+	switch {
+	case eventLogView == nil:
+		return false
+	case event.Key() == tcell.KeyCtrlC || event.Key() == tcell.KeyCtrlL:
+		return false
+	case event.Key() == tcell.KeyRune && event.Rune() == 'q':
+		return false
+	case event.Key() == tcell.KeyEsc:
+		closeEventLog(app)
+		return true
+	}
+
+	eventLogView.InputHandler()(event, func(p tview.Primitive) {})
+	return true
+}
@@ -0,0 +1,715 @@
+// jobs.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// jobKeys are the shortcuts of the job list.
+var jobKeys = []keyHelp{
+	{"Enter", "Job actions"},
+	{"o", "Cycle sort order"},
+	{"y", "Copy web URL"},
+	{"Y", "Copy a glab or curl command"},
+	{"b", "Open in browser"},
+	{"r", "Refresh"},
+	{"Esc", "Back"},
+}
+
+// jobOrders are the orders the job list cycles through with o. In stage
+// order the jobs are grouped under their stages.
+var jobOrders = []string{"stage", "duration", "status"}
+
+func listPipelineJobs(ctx context.Context, gl GitLab, projectID, pipelineID string) ([]*gitlab.Job, error) {
+	id, err := toInt(pipelineID)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching jobs for project %s: %w", projectID, err)
+	}
+
+	var pipelineJobs []*gitlab.Job
+	listOptions := &gitlab.ListJobsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: perPage,
+			Page:    1,
+		},
+	}
+
+	for {
+		page, resp, err := gl.ListPipelineJobs(ctx, projectID, id, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching jobs for project %s and pipeline %s: %w", projectID, pipelineID, err)
+		}
+
+		pipelineJobs = append(pipelineJobs, page...)
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+
+	return pipelineJobs, nil
+}
+
+// withPipelineCrumbs returns the breadcrumb of a pipeline: crumbs, the
+// breadcrumb of its project, followed by its name, if any, and ID.
+func withPipelineCrumbs(crumbs []string, pipelineID, pipelineName string) []string {
+	if pipelineName != "" {
+		crumbs = withCrumb(crumbs, pipelineName)
+	}
+	return withCrumb(crumbs, "#"+pipelineID)
+}
+
+// buildPipelineHeader returns the bar above the views of a pipeline telling
+// its status, ref and commit, along with a func that fetches them again.
+// They're fetched in the background; until then the bar only has the ID.
+func buildPipelineHeader(app *tview.Application, projectID, pipelineID string) (*tview.TextView, func()) {
+	header := tview.NewTextView().
+		SetDynamicColors(true).
+		SetText("Pipeline #" + pipelineID)
+
+	reload := func() {
+		id, err := toInt(pipelineID)
+		if err != nil {
+			return
+		}
+
+		go func() {
+			// The header is only extra information, so errors leave it as
+			// it is.
+			pipeline, _, err := gitlabClient.GetPipeline(context.Background(), projectID, id)
+			if err != nil {
+				return
+			}
+			commit, _, err := gitlabClient.GetCommit(context.Background(), projectID, pipeline.SHA)
+			if err != nil {
+				commit = nil
+			}
+
+			app.QueueUpdateDraw(func() {
+				header.SetText(pipelineHeader(pipeline, commit))
+			})
+		}()
+	}
+	reload()
+
+	return header, reload
+}
+
+// pipelineHeader returns the text of the header of a pipeline, e.g.
+// "Pipeline #12 running on main at 1a2b3c4d Fix the build - by alice".
+// commit may be nil.
+func pipelineHeader(pipeline *gitlab.Pipeline, commit *gitlab.Commit) string {
+	text := fmt.Sprintf("Pipeline #%d %s%s[-] on %s at %.8s", pipeline.ID, statusColor(pipeline.Status), pipeline.Status,
+		tview.Escape(pipeline.Ref), pipeline.SHA)
+	if commit != nil {
+		text += " " + tview.Escape(commit.Title)
+	}
+	if pipeline.User != nil {
+		text += " - by " + tview.Escape(pipeline.User.Username)
+	} else {
+		text += " - " + sourceLabel(pipeline.Source)
+	}
+	return text
+}
+
+// rebuildJobListView returns the list of the jobs of a pipeline, or only of
+// its stage named stage if that isn't empty. crumbs is the breadcrumb of the
+// project.
+func rebuildJobListView(app *tview.Application, pipelineJobs []*gitlab.Job, projectID, pipelineID, pipelineName, stage string, crumbs []string) *tview.Flex {
+	pipelineCrumbs := withPipelineCrumbs(crumbs, pipelineID, pipelineName)
+	if stage != "" {
+		pipelineCrumbs = withCrumb(pipelineCrumbs, stage)
+	}
+
+	order := 0
+
+	jobList := tview.NewList().ShowSecondaryText(false)
+	jobList.SetBorder(true)
+	flex := tview.NewFlex()
+	header, reloadHeader := buildPipelineHeader(app, projectID, pipelineID)
+
+	// rows holds the job shown in each list row, or nil for stage headers.
+	var rows []*gitlab.Job
+
+	// emptyMessage takes the place of the list while it's empty. The list
+	// keeps the focus, so its keys still work.
+	emptyMessage := buildEmptyMessage("No jobs in this pipeline")
+
+	setJobs := func(jobs []*gitlab.Job) {
+		pipelineJobs = jobs
+		rows = nil
+		jobList.Clear()
+		title := "#" + pipelineID
+		if stage != "" {
+			title += " " + stage
+		}
+		jobList.SetTitle(tview.Escape(fmt.Sprintf(" Jobs of %s (%d) - order: %s", title, len(pipelineJobs), jobOrders[order])) + " (o to change) ")
+
+		if jobOrders[order] == "stage" {
+			for _, stage := range groupJobsByStage(pipelineJobs) {
+				jobList.AddItem(boldColorTag(theme.Accent)+"Stage: "+tview.Escape(stage.name), "", 0, nil)
+				rows = append(rows, nil)
+
+				for _, job := range stage.jobs {
+					jobInfo := fmt.Sprintf("  Job ID: %d \n  Name: %s \n  Status: %s \n  Duration: %s \n  Runner: %s \n  Tags: %s",
+						job.ID, job.Name, jobStatusText(job), jobDuration(job), jobRunner(job), jobTags(job))
+					jobList.AddItem(jobInfo, "", 0, nil)
+					rows = append(rows, job)
+				}
+			}
+		} else {
+			for _, job := range sortJobs(pipelineJobs, jobOrders[order]) {
+				jobInfo := fmt.Sprintf("Job ID: %d \nName: %s \nStage: %s \nStatus: %s \nDuration: %s \nRunner: %s \nTags: %s",
+					job.ID, job.Name, job.Stage, jobStatusText(job), jobDuration(job), jobRunner(job), jobTags(job))
+				jobList.AddItem(jobInfo, "", 0, nil)
+				rows = append(rows, job)
+			}
+		}
+
+		if len(rows) == 0 {
+			flex.ResizeItem(jobList, 0, 0).ResizeItem(emptyMessage, 0, 1)
+		} else {
+			flex.ResizeItem(jobList, 0, 1).ResizeItem(emptyMessage, 0, 0)
+		}
+	}
+
+	// Stage headers can't be selected; moving onto one skips to the next job
+	// in the direction of the move.
+	previousIndex := 0
+	jobList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		if index < len(rows) && rows[index] == nil {
+			step := 1
+			if index < previousIndex {
+				step = -1
+			}
+			if row := jobRow(rows, index, step); row != index {
+				jobList.SetCurrentItem(row)
+				return
+			}
+		}
+		previousIndex = index
+	})
+
+	// refresh re-fetches the jobs and updates the list in place, keeping the
+	// selected job and the stage filter.
+	refresh := func() {
+		var jobs []*gitlab.Job
+		loadAsync(app, "Loading jobs...", flex, func(ctx context.Context) (err error) {
+			jobs, err = listPipelineJobs(ctx, gitlabClient, projectID, pipelineID)
+			return err
+		}, func(err error) {
+			if err != nil {
+				showError(app, err.Error(), flex)
+				return
+			}
+			reloadHeader()
+
+			if stage != "" {
+				jobs = jobsOfStage(jobs, stage)
+			}
+
+			index := jobList.GetCurrentItem()
+			selectedID := 0
+			if index < len(rows) && rows[index] != nil {
+				selectedID = rows[index].ID
+			}
+			setJobs(jobs)
+			jobList.SetCurrentItem(findJobRow(rows, selectedID, index))
+			app.SetRoot(flex, true)
+		})
+	}
+
+	// replaceJob puts job in the place of the job with oldID, keeping the
+	// selection on it. It does nothing if that job is gone, e.g. after a
+	// refresh.
+	replaceJob := func(oldID int, job *gitlab.Job) {
+		jobs := make([]*gitlab.Job, len(pipelineJobs))
+		copy(jobs, pipelineJobs)
+
+		found := false
+		for i, j := range jobs {
+			if j.ID == oldID {
+				jobs[i] = job
+				found = true
+			}
+		}
+		if !found {
+			return
+		}
+
+		index := jobList.GetCurrentItem()
+		selectedID := 0
+		if index < len(rows) && rows[index] != nil {
+			selectedID = rows[index].ID
+			if selectedID == oldID {
+				selectedID = job.ID
+			}
+		}
+		setJobs(jobs)
+		jobList.SetCurrentItem(findJobRow(rows, selectedID, index))
+	}
+
+	jobList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		selectedJob := rows[index]
+		if selectedJob == nil {
+			return
+		}
+
+		actions := []string{"Logs", "Retry", "Cancel", "Back"}
+		text := fmt.Sprintf("Select Action for Job %d", selectedJob.ID)
+		if times := jobTimes(selectedJob); times != "" {
+			text += "\n\n" + times
+		}
+		if hasArtifacts(selectedJob) {
+			actions = []string{"Logs", "Retry", "Cancel", "Download Artifacts", "Back"}
+			text += "\n\n" + artifactsInfo(selectedJob)
+		}
+		if selectedJob.Status == "manual" {
+			actions = append([]string{"Play"}, actions...)
+		}
+
+		jobActionModal := tview.NewModal().
+			SetText(text).
+			AddButtons(actions)
+
+		returnToJobList := func() {
+			app.SetRoot(flex, true)
+		}
+
+		// runAction runs a job action in the background, logs it as done and
+		// calls after once it succeeded.
+		runAction := func(msg, done string, action func(ctx context.Context, projectID, jobID string) error, after func()) {
+			loadAsync(app, msg, flex, func(ctx context.Context) error {
+				return action(ctx, projectID, strconv.Itoa(selectedJob.ID))
+			}, func(err error) {
+				if err != nil {
+					showError(app, err.Error(), flex)
+					return
+				}
+				logEvent("%s job %s (%d)", done, selectedJob.Name, selectedJob.ID)
+				after()
+			})
+		}
+
+		jobActionModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case "Logs":
+				fetchAndDisplayJobLogs(app, projectID, strconv.Itoa(selectedJob.ID), false, false, withCrumb(pipelineCrumbs, selectedJob.Name), flex)
+			case "Retry":
+				confirm(app, fmt.Sprintf("Retry job %s (%d)?", tview.Escape(selectedJob.Name), selectedJob.ID), flex, func() {
+					var retried *gitlab.Job
+					loadAsync(app, "Retrying job...", flex, func(ctx context.Context) (err error) {
+						retried, err = retryJob(ctx, projectID, strconv.Itoa(selectedJob.ID))
+						return err
+					}, func(err error) {
+						if err != nil {
+							showError(app, err.Error(), flex)
+							return
+						}
+
+						logEvent("Retried job %s (%d) as job %d", selectedJob.Name, selectedJob.ID, retried.ID)
+
+						// The retry is a new job; it takes the place of the
+						// old one and is followed until it's picked up.
+						replaceJob(selectedJob.ID, retried)
+						returnToJobList()
+						go pollRetriedJob(app, projectID, retried, func(job *gitlab.Job) {
+							replaceJob(job.ID, job)
+						})
+					})
+				})
+			case "Download Artifacts":
+				downloadArtifacts(app, projectID, strconv.Itoa(selectedJob.ID), flex)
+			case "Play":
+				confirm(app, fmt.Sprintf("Run job %s (%d)?", tview.Escape(selectedJob.Name), selectedJob.ID), flex, func() {
+					runAction("Starting job...", "Started", playJob, refresh)
+				})
+			case "Cancel":
+				confirm(app, fmt.Sprintf("Cancel job %s (%d)?", tview.Escape(selectedJob.Name), selectedJob.ID), flex, func() {
+					runAction("Canceling job...", "Canceled", cancelJob, refresh)
+				})
+			default:
+				returnToJobList()
+			}
+		})
+
+		app.SetRoot(jobActionModal, false).SetFocus(jobActionModal)
+	})
+
+	jobList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			popView(app)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, jobKeys, flex)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'o':
+			order = (order + 1) % len(jobOrders)
+			setJobs(pipelineJobs)
+			jobList.SetCurrentItem(jobRow(rows, 0, 1))
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'y':
+			if index := jobList.GetCurrentItem(); index < len(rows) && rows[index] != nil {
+				copyToClipboard(app, rows[index].WebURL, "the job URL", flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'Y':
+			if index := jobList.GetCurrentItem(); index < len(rows) && rows[index] != nil {
+				job := rows[index]
+				glab, curl := jobCommands(projectID, job.ID, job.WebURL)
+				copyCommand(app, "job "+tview.Escape(job.Name), glab, curl, flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'b':
+			if index := jobList.GetCurrentItem(); index < len(rows) && rows[index] != nil {
+				openInBrowser(app, rows[index].WebURL, flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'r':
+			refresh()
+			return nil
+		}
+		return event
+	})
+
+	flex.SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, withCrumb(pipelineCrumbs, "jobs")), 1, 0, false).
+		AddItem(header, 1, 0, false).
+		AddItem(jobList, 0, 1, true).
+		AddItem(emptyMessage, 0, 0, false).
+		AddItem(buildFooter(app, func() {
+			popView(app)
+		}), 1, 0, false)
+
+	setJobs(pipelineJobs)
+	jobList.SetCurrentItem(jobRow(rows, 0, 1))
+
+	return flex
+}
+
+// jobStage is a pipeline stage with its jobs.
+type jobStage struct {
+	name string
+	jobs []*gitlab.Job
+}
+
+// groupJobsByStage groups jobs by stage. Stages are ordered by their first
+// job in jobs.
+func groupJobsByStage(jobs []*gitlab.Job) []*jobStage {
+	var stages []*jobStage
+	byName := make(map[string]*jobStage)
+	for _, job := range jobs {
+		stage, ok := byName[job.Stage]
+		if !ok {
+			stage = &jobStage{name: job.Stage}
+			byName[job.Stage] = stage
+			stages = append(stages, stage)
+		}
+		stage.jobs = append(stage.jobs, job)
+	}
+	return stages
+}
+
+// jobsOfStage returns the jobs of the stage named stage.
+func jobsOfStage(jobs []*gitlab.Job, stage string) []*gitlab.Job {
+	var stageJobs []*gitlab.Job
+	for _, job := range jobs {
+		if job.Stage == stage {
+			stageJobs = append(stageJobs, job)
+		}
+	}
+	return stageJobs
+}
+
+// jobDuration returns how long a job ran, or how long it has been waiting
+// for a runner while it's pending.
+func jobDuration(job *gitlab.Job) string {
+	switch {
+	case job.Status == "pending" && job.QueuedDuration > 0:
+		return "queued for " + humanizeDuration(job.QueuedDuration)
+	case job.Status == "pending":
+		return "queued"
+	case job.Duration == 0:
+		return "-"
+	}
+	return humanizeDuration(job.Duration)
+}
+
+// jobTimes tells how long a job was queued, from its creation until a runner
+// started it, apart from how long it then ran, to tell a lack of runners
+// from a slow job. The queued time includes waiting for earlier stages.
+// Times of jobs still queued or running are counted up to now; it's empty
+// for jobs that were never started nor are waiting to be.
+func jobTimes(job *gitlab.Job) string {
+	if job.CreatedAt == nil {
+		return ""
+	}
+
+	if job.StartedAt == nil {
+		if job.Status != "created" && job.Status != "pending" {
+			return ""
+		}
+		return "Queued: " + humanizeDuration(time.Since(*job.CreatedAt).Seconds()) + " so far"
+	}
+
+	text := "Queued: " + humanizeDuration(job.StartedAt.Sub(*job.CreatedAt).Seconds())
+	if job.FinishedAt == nil {
+		return text + ", running: " + humanizeDuration(time.Since(*job.StartedAt).Seconds()) + " so far"
+	}
+	return text + ", ran: " + humanizeDuration(job.FinishedAt.Sub(*job.StartedAt).Seconds())
+}
+
+// jobRunner returns the runner that picked up a job. Pending jobs without
+// one are highlighted, as they may be waiting for a runner with matching
+// tags that doesn't exist.
+func jobRunner(job *gitlab.Job) string {
+	runner := job.Runner
+	if runner.ID == 0 {
+		if job.Status == "pending" {
+			return colorTag(theme.Failed) + "none - no runner has picked this job up yet[-]"
+		}
+		return "-"
+	}
+
+	name := runner.Description
+	if name == "" {
+		name = fmt.Sprintf("#%d", runner.ID)
+	}
+	if runner.IsShared {
+		name += " (shared)"
+	}
+	return tview.Escape(name)
+}
+
+// jobTags returns the runner tags a job requires.
+func jobTags(job *gitlab.Job) string {
+	if len(job.TagList) == 0 {
+		return "none"
+	}
+	return tview.Escape(strings.Join(job.TagList, ", "))
+}
+
+// jobStatusRanks orders job statuses for sorting by status, most
+// interesting first.
+var jobStatusRanks = map[string]int{
+	"failed":   0,
+	"running":  1,
+	"pending":  2,
+	"manual":   3,
+	"canceled": 4,
+	"skipped":  5,
+	"created":  6,
+	"success":  7,
+}
+
+// sortJobs returns the jobs sorted by duration, longest first, or by
+// status.
+func sortJobs(jobs []*gitlab.Job, order string) []*gitlab.Job {
+	sorted := append([]*gitlab.Job(nil), jobs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if order == "duration" {
+			return sorted[i].Duration > sorted[j].Duration
+		}
+
+		rankI, ok := jobStatusRanks[sorted[i].Status]
+		if !ok {
+			rankI = len(jobStatusRanks)
+		}
+		rankJ, ok := jobStatusRanks[sorted[j].Status]
+		if !ok {
+			rankJ = len(jobStatusRanks)
+		}
+		return rankI < rankJ
+	})
+	return sorted
+}
+
+// jobRow returns the row closest to index in the direction of step that
+// shows a job, looking the other way if there is none. It returns index if
+// rows has no jobs at all.
+func jobRow(rows []*gitlab.Job, index, step int) int {
+	for _, step := range []int{step, -step} {
+		for row := index; row >= 0 && row < len(rows); row += step {
+			if rows[row] != nil {
+				return row
+			}
+		}
+	}
+	return index
+}
+
+// findJobRow returns the row of the job with the ID jobID or, if it's gone,
+// the job row nearest to index.
+func findJobRow(rows []*gitlab.Job, jobID, index int) int {
+	for row, job := range rows {
+		if job != nil && job.ID == jobID {
+			return row
+		}
+	}
+
+	if index >= len(rows) {
+		index = len(rows) - 1
+	}
+	if index < 0 {
+		return 0
+	}
+	return jobRow(rows, index, 1)
+}
+
+// retryJob retries a job and returns the job running it again.
+func retryJob(ctx context.Context, projectID, jobID string) (*gitlab.Job, error) {
+	id, err := toInt(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("Error retrying job: %w", err)
+	}
+
+	job, _, err := gitlabClient.RetryJob(ctx, projectID, id)
+	if err != nil {
+		return nil, fmt.Errorf("Error retrying job: %w", err)
+	}
+
+	return job, nil
+}
+
+// retryPollDelays are the waits between the checks of a retried job,
+// backing off while it waits for a runner.
+var retryPollDelays = []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second}
+
+// pollRetriedJob checks the status of a retried job a few times, handing
+// every change to update on the UI goroutine, until it runs, finishes or
+// the checks run out.
+func pollRetriedJob(app *tview.Application, projectID string, job *gitlab.Job, update func(job *gitlab.Job)) {
+	status := job.Status
+	for _, delay := range retryPollDelays {
+		time.Sleep(delay)
+
+		polled, _, err := gitlabClient.GetJob(context.Background(), projectID, job.ID)
+		if err != nil {
+			logError("Error polling retried job %d: %v", job.ID, err)
+			continue
+		}
+
+		if polled.Status != status {
+			status = polled.Status
+			app.QueueUpdateDraw(func() {
+				update(polled)
+			})
+		}
+		if status == "running" || isTerminalStatus(status) {
+			return
+		}
+	}
+}
+
+func cancelJob(ctx context.Context, projectID, jobID string) error {
+	id, err := toInt(jobID)
+	if err != nil {
+		return fmt.Errorf("Error canceling job: %w", err)
+	}
+
+	_, _, err = gitlabClient.CancelJob(ctx, projectID, id)
+	if err != nil {
+		return fmt.Errorf("Error canceling job: %w", err)
+	}
+
+	return nil
+}
+
+func playJob(ctx context.Context, projectID, jobID string) error {
+	id, err := toInt(jobID)
+	if err != nil {
+		return fmt.Errorf("Error playing job: %w", err)
+	}
+
+	_, _, err = gitlabClient.PlayJob(ctx, projectID, id, &gitlab.PlayJobOptions{})
+	if err != nil {
+		return fmt.Errorf("Error playing job: %w", err)
+	}
+
+	return nil
+}
+
+// hasArtifacts reports whether a job has an artifacts archive to download.
+func hasArtifacts(job *gitlab.Job) bool {
+	return job.ArtifactsFile.Filename != ""
+}
+
+// artifactsInfo returns the size of the artifacts of a job and when they
+// expire.
+func artifactsInfo(job *gitlab.Job) string {
+	expiry := "never expire"
+	if job.ArtifactsExpireAt != nil {
+		expiry = "expire " + job.ArtifactsExpireAt.Local().Format("2006-01-02 15:04")
+		if left := time.Until(*job.ArtifactsExpireAt); left > 0 && left < 24*time.Hour {
+			expiry += " (in " + left.Round(time.Minute).String() + ")"
+		}
+	}
+	return fmt.Sprintf("Artifacts: %s, %s", formatSize(job.ArtifactsFile.Size), expiry)
+}
+
+// downloadArtifacts saves the artifacts archive of a job as
+// artifacts-<jobID>.zip in the working directory.
+func downloadArtifacts(app *tview.Application, projectID, jobID string, returnTo tview.Primitive) {
+	var path string
+	noArtifacts := false
+
+	loadAsync(app, "Downloading artifacts...", returnTo, func(ctx context.Context) error {
+		id, err := toInt(jobID)
+		if err != nil {
+			return fmt.Errorf("Error downloading artifacts: %w", err)
+		}
+
+		artifacts, resp, err := gitlabClient.GetJobArtifacts(ctx, projectID, id)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				noArtifacts = true
+				return nil
+			}
+			return fmt.Errorf("Error downloading artifacts: %w", err)
+		}
+
+		path, err = filepath.Abs(fmt.Sprintf("artifacts-%s.zip", jobID))
+		if err != nil {
+			return fmt.Errorf("Error saving artifacts: %w", err)
+		}
+
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("Error saving artifacts: %w", err)
+		}
+		defer file.Close()
+
+		if _, err := io.Copy(file, artifacts); err != nil {
+			return fmt.Errorf("Error saving artifacts: %w", err)
+		}
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("Error saving artifacts: %w", err)
+		}
+		return nil
+	}, func(err error) {
+		switch {
+		case err != nil:
+			showError(app, err.Error(), returnTo)
+		case noArtifacts:
+			showMessage(app, fmt.Sprintf("Job %s has no artifacts.", jobID), returnTo)
+		default:
+			logEvent("Artifacts of job %s saved to %s", jobID, path)
+			showMessage(app, "Artifacts saved to "+path, returnTo)
+		}
+	})
+}
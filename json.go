@@ -0,0 +1,81 @@
+// json.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// scriptClient returns the client for output printed instead of opening the
+// viewer, such as --json. Without the profile picker there must be a single
+// profile, or one chosen with --profile. flagName names the flag in errors.
+func scriptClient(flagName string) (GitLab, error) {
+	if len(profiles) > 1 {
+		return nil, fmt.Errorf("%s needs --profile to choose between %d profiles", flagName, len(profiles))
+	}
+
+	gl, err := newProfileClient(profiles[0])
+	if err != nil {
+		return nil, fmt.Errorf("Error creating GitLab client: %w", err)
+	}
+	return gl, nil
+}
+
+// printJSON prints the pipelines selected by --project and --branch, the
+// jobs of --pipeline or the --job, to stdout as JSON, in the form the API
+// returns them. Only the latest page of pipelines is printed.
+func printJSON() error {
+	gl, err := scriptClient("--json")
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if loadTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, loadTimeout)
+		defer cancel()
+	}
+	project, err := resolveProject(ctx, gl, *projectFlag)
+	if err != nil {
+		return err
+	}
+	projectID := strconv.Itoa(project.ID)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if *jobFlag != 0 {
+		job, _, err := gl.GetJob(ctx, projectID, *jobFlag)
+		if err != nil {
+			return fmt.Errorf("Error fetching job %d: %w", *jobFlag, err)
+		}
+		return encoder.Encode(job)
+	}
+
+	if *pipelineFlag != 0 {
+		jobs, err := listPipelineJobs(ctx, gl, projectID, strconv.Itoa(*pipelineFlag))
+		if err != nil {
+			return err
+		}
+		// Empty lists are printed as [] rather than null.
+		if jobs == nil {
+			jobs = []*gitlab.Job{}
+		}
+		return encoder.Encode(jobs)
+	}
+
+	pipelines, _, err := listBranchPipelines(ctx, gl, projectID, *branchFlag, pipelineQuery{page: 1, order: pipelineOrders[0]})
+	if err != nil {
+		return fmt.Errorf("Error fetching pipelines for project %s on %s: %w", *projectFlag, branchLabel(*branchFlag), err)
+	}
+	if pipelines == nil {
+		pipelines = []*gitlab.PipelineInfo{}
+	}
+	return encoder.Encode(pipelines)
+}
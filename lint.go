@@ -0,0 +1,119 @@
+// lint.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// lintKeys are the shortcuts of the lint result view.
+var lintKeys = []keyHelp{
+	{"r", "Validate again"},
+	{"Esc", "Back"},
+}
+
+// fetchAndShowLint validates the .gitlab-ci.yml of a project on its default
+// branch and shows the errors and warnings. crumbs is the breadcrumb of the
+// project.
+func fetchAndShowLint(app *tview.Application, projectID string, crumbs []string, returnTo tview.Primitive) {
+	var result *gitlab.ProjectLintResult
+	loadAsync(app, "Validating CI/CD configuration...", returnTo, func(ctx context.Context) (err error) {
+		result, err = lintProject(ctx, gitlabClient, projectID)
+		return err
+	}, func(err error) {
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
+
+		showLint(app, projectID, result, withCrumb(crumbs, "CI lint"))
+	})
+}
+
+func lintProject(ctx context.Context, gl GitLab, projectID string) (*gitlab.ProjectLintResult, error) {
+	result, _, err := gl.ProjectLint(ctx, projectID, &gitlab.ProjectLintOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("Error validating the CI/CD configuration of project %s: %w", projectID, err)
+	}
+	return result, nil
+}
+
+// showLint shows the result of validating the CI/CD configuration of a
+// project, errors in the failed color and warnings in the warning color.
+func showLint(app *tview.Application, projectID string, result *gitlab.ProjectLintResult, crumbs []string) {
+	lintView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetWordWrap(true)
+	lintView.SetBorder(true).SetTitle(" .gitlab-ci.yml ")
+
+	flex := tview.NewFlex()
+
+	setResult := func(result *gitlab.ProjectLintResult) {
+		lintView.SetText(lintText(result)).ScrollToBeginning()
+	}
+
+	lintView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			popView(app)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, lintKeys, flex)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'r':
+			var result *gitlab.ProjectLintResult
+			loadAsync(app, "Validating CI/CD configuration...", flex, func(ctx context.Context) (err error) {
+				result, err = lintProject(ctx, gitlabClient, projectID)
+				return err
+			}, func(err error) {
+				app.SetRoot(flex, true)
+				if err != nil {
+					showError(app, err.Error(), flex)
+					return
+				}
+				setResult(result)
+			})
+			return nil
+		}
+		return event
+	})
+
+	flex.SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, crumbs), 1, 0, false).
+		AddItem(lintView, 0, 1, true).
+		AddItem(buildFooter(app, func() {
+			popView(app)
+		}), 1, 0, false)
+
+	setResult(result)
+	pushView(app, flex)
+}
+
+// lintText returns the text of a lint result: whether the configuration is
+// valid, followed by its errors and warnings.
+func lintText(result *gitlab.ProjectLintResult) string {
+	var text strings.Builder
+	if result.Valid {
+		fmt.Fprintf(&text, "%sThe configuration is valid.[-]\n", colorTag(theme.Success))
+	} else {
+		fmt.Fprintf(&text, "%sThe configuration is invalid.[-]\n", colorTag(theme.Failed))
+	}
+
+	for _, msg := range result.Errors {
+		fmt.Fprintf(&text, "\n%sError:[-] %s\n", colorTag(theme.Failed), tview.Escape(msg))
+	}
+	for _, msg := range result.Warnings {
+		fmt.Fprintf(&text, "\n%sWarning:[-] %s\n", colorTag(theme.Warning), tview.Escape(msg))
+	}
+
+	if len(result.Errors) == 0 && len(result.Warnings) == 0 {
+		text.WriteString("\nNo errors or warnings.\n")
+	}
+	return text.String()
+}
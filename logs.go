@@ -0,0 +1,578 @@
+// logs.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// logKeys are the shortcuts of the log view. Paging is handled by the
+// TextView itself.
+var logKeys = []keyHelp{
+	{"PgUp/PgDn", "Scroll by a screen"},
+	{"Home/End", "Jump to the top / bottom"},
+	{"/", "Search"},
+	{"n / N", "Next / previous match"},
+	{"f", "Follow running job"},
+	{"w", "Save log to a file"},
+	{"W", "Toggle line wrapping"},
+	{"l", "Toggle line numbers"},
+	{"L", "Load the full log"},
+	{"y", "Copy the line of the match, or the log"},
+	{"Esc", "Back"},
+}
+
+const defaultLogLimitKB = 1024
+
+// logLimit is how many bytes of a log are shown at most, taken from its end.
+// 0 shows logs in full.
+var logLimit = defaultLogLimitKB * 1024
+
+// fetchAndDisplayJobLogs shows the log of a job, or its last logLimit bytes
+// unless full is set. crumbs is the breadcrumb of the job.
+func fetchAndDisplayJobLogs(app *tview.Application, projectID, jobID string, full, follow bool, crumbs []string, returnTo tview.Primitive) {
+	var logs []byte
+	var skipped int
+
+	// noLogs is set when the job has no log yet, typically because it hasn't
+	// started; status is then the status of the job, if known.
+	var noLogs bool
+	var status string
+
+	loadAsync(app, "Loading logs...", returnTo, func(ctx context.Context) error {
+		id, err := toInt(jobID)
+		if err != nil {
+			return fmt.Errorf("Error fetching logs: %w", err)
+		}
+
+		logsReader, resp, err := gitlabClient.GetTraceFile(ctx, projectID, id)
+		if err != nil && (resp == nil || resp.StatusCode != http.StatusNotFound) {
+			return fmt.Errorf("Error fetching logs: %w", err)
+		}
+		if err != nil || logsReader.Len() == 0 {
+			noLogs = true
+			// The status only explains the missing log, so it's left out
+			// when it can't be fetched.
+			if job, _, err := gitlabClient.GetJob(ctx, projectID, id); err == nil {
+				status = job.Status
+			}
+			return nil
+		}
+
+		limit := logLimit
+		if full {
+			limit = 0
+		}
+		logs, skipped, err = readLogTail(logsReader, limit)
+		if err != nil {
+			return fmt.Errorf("Error reading logs: %w", err)
+		}
+		return nil
+	}, func(err error) {
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
+
+		if noLogs {
+			showNoLogs(app, status, func() {
+				fetchAndDisplayJobLogs(app, projectID, jobID, full, follow, crumbs, returnTo)
+			}, returnTo)
+			return
+		}
+
+		displayJobLogs(app, projectID, jobID, logs, skipped, follow, crumbs)
+	})
+}
+
+// showNoLogs tells that a job with status has no log, in a modal that either
+// calls retry or restores returnTo.
+func showNoLogs(app *tview.Application, status string, retry func(), returnTo tview.Primitive) {
+	msg := "No logs yet - the job hasn't started."
+	switch {
+	case isTerminalStatus(status):
+		msg = fmt.Sprintf("This job has no log. It's %s.", status)
+	case status == "running":
+		msg = "No logs yet - the job has just started."
+	case status != "":
+		msg = fmt.Sprintf("No logs yet - the job hasn't started. It's %s.", status)
+	}
+
+	modal := tview.NewModal().
+		SetText(msg).
+		AddButtons([]string{"Retry", "Back"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Retry" {
+				retry()
+				return
+			}
+			app.SetRoot(returnTo, true)
+		})
+
+	app.SetRoot(modal, false).SetFocus(modal)
+}
+
+// readLogTail reads the last limit bytes of a log, starting at a line break so
+// no line or escape sequence is cut in half, and returns them along with the
+// number of bytes skipped. A limit of 0 reads the whole log.
+func readLogTail(r *bytes.Reader, limit int) ([]byte, int, error) {
+	skipped := 0
+	if limit > 0 && r.Len() > limit {
+		skipped = r.Len() - limit
+		if _, err := r.Seek(int64(skipped), io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	logs, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if skipped > 0 {
+		if i := bytes.IndexByte(logs, '\n'); i >= 0 {
+			logs = logs[i+1:]
+			skipped += i + 1
+		}
+	}
+	return logs, skipped, nil
+}
+
+// formatSize formats a number of bytes, e.g. 1536 as "1.5 KB".
+func formatSize(n int) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+// displayJobLogs shows logs, the end of the log of a job after the first
+// skipped bytes. With follow the log is followed from the start.
+func displayJobLogs(app *tview.Application, projectID, jobID string, logs []byte, skipped int, follow bool, crumbs []string) {
+	// content is the text of logView without line numbers.
+	content := ansiToTview(string(logs))
+
+	logView := tview.NewTextView().
+		SetText(content).
+		SetScrollable(true).
+		SetDynamicColors(true).
+		SetRegions(true).
+		SetWordWrap(true)
+
+	// Long lines are wrapped unless turned off with W; logView then
+	// scrolls sideways. numbered prefixes the lines with their numbers,
+	// nextLine being the number of the next line appended while following.
+	wrap := true
+	numbered := false
+	nextLine := 1
+
+	searchInput := tview.NewInputField().
+		SetLabel("Search: ")
+
+	// shown is how many bytes of the log have been written to logView,
+	// counting the skipped ones. While following, only the bytes after it
+	// are appended.
+	shown := skipped + len(logs)
+	var stopFollow context.CancelFunc
+
+	// setText replaces the text of logView. While following it sticks to the
+	// end, otherwise it stays where the user scrolled to.
+	setText := func(text string) {
+		row, column := logView.GetScrollOffset()
+		content = text
+		if numbered {
+			text, nextLine = numberLines(text, 1)
+		}
+		logView.SetText(text)
+		if stopFollow != nil {
+			logView.ScrollToEnd()
+		} else {
+			logView.ScrollTo(row, column)
+		}
+	}
+
+	stopFollowing := func() {
+		if stopFollow != nil {
+			stopFollow()
+			stopFollow = nil
+		}
+		logView.SetBorder(false).SetTitle("")
+	}
+
+	startFollowing := func() error {
+		id, err := toInt(jobID)
+		if err != nil {
+			return fmt.Errorf("Error following job: %w", err)
+		}
+
+		var ctx context.Context
+		ctx, stopFollow = context.WithCancel(context.Background())
+		logView.SetBorder(true).SetTitle(" Following - f to stop ")
+		// The TextView keeps tracking the end on writes until the user
+		// scrolls up.
+		logView.ScrollToEnd()
+
+		go followJobTrace(ctx, app, projectID, id, func(trace []byte, finished bool) {
+			if len(trace) >= skipped {
+				logs = trace[skipped:]
+			}
+			if len(trace) > shown {
+				chunk := trace[shown:]
+				// Hold back incomplete lines so escape sequences aren't split.
+				if !finished {
+					chunk = chunk[:bytes.LastIndexByte(chunk, '\n')+1]
+				}
+				text := ansiToTview(string(chunk))
+				content += text
+				if numbered {
+					text, nextLine = numberLines(text, nextLine)
+				}
+				fmt.Fprint(logView, text)
+				shown += len(chunk)
+			}
+			if finished {
+				stopFollowing()
+			}
+		})
+		return nil
+	}
+
+	leave := func() {
+		stopFollowing()
+		popView(app)
+	}
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, withCrumb(crumbs, "logs")), 1, 0, false)
+
+	if skipped > 0 {
+		banner := tview.NewTextView().
+			SetDynamicColors(true).
+			SetText(fmt.Sprintf("%sLog truncated: showing the last %s of %s - press L to load the full log[-]",
+				colorTag(theme.Running), formatSize(len(logs)), formatSize(skipped+len(logs))))
+		flex.AddItem(banner, 1, 0, false)
+	}
+
+	flex.
+		AddItem(logView, 0, 1, true).
+		AddItem(searchInput, 0, 0, false).
+		AddItem(buildFooter(app, leave), 1, 0, false)
+
+	// Matches of the current search term are regions "0" to matchCount-1.
+	term := ""
+	matchCount, currentMatch := 0, 0
+
+	showMatch := func(index int) {
+		if matchCount == 0 {
+			return
+		}
+		currentMatch = (index + matchCount) % matchCount
+		logView.Highlight(strconv.Itoa(currentMatch)).ScrollToHighlight()
+	}
+
+	// The search input stays in the layout but only takes up space while
+	// it's open.
+	closeSearch := func() {
+		flex.ResizeItem(searchInput, 0, 0)
+		app.SetFocus(logView)
+	}
+
+	searchInput.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			var text string
+			term = searchInput.GetText()
+			text, matchCount = highlightTrace(string(logs), term)
+			setText(text)
+			shown = skipped + len(logs)
+			closeSearch()
+			logView.Highlight()
+			showMatch(0)
+		case tcell.KeyEsc:
+			closeSearch()
+		}
+	})
+
+	logView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			leave()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, logKeys, flex)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'w':
+			save := func(logs []byte) {
+				path, err := saveJobLog(jobID, logs)
+				if err != nil {
+					showError(app, err.Error(), flex)
+				} else {
+					showMessage(app, "Log saved to "+path, flex)
+				}
+			}
+
+			// Only the tail of a truncated log is shown; the file gets all
+			// of it.
+			if skipped == 0 {
+				save(logs)
+				return nil
+			}
+			var full []byte
+			loadAsync(app, "Loading the full log...", flex, func(ctx context.Context) error {
+				var err error
+				full, err = fetchJobLog(ctx, projectID, jobID)
+				return err
+			}, func(err error) {
+				if err != nil {
+					showError(app, err.Error(), flex)
+					return
+				}
+				save(full)
+			})
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'W':
+			wrap = !wrap
+			logView.SetWrap(wrap)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'l':
+			numbered = !numbered
+			setText(content)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'y':
+			if line, ok := matchLine(string(logs), term, currentMatch); ok && matchCount > 0 {
+				copyToClipboard(app, line, "the matching line", flex)
+			} else {
+				copyToClipboard(app, ansiSequence.ReplaceAllString(sectionMarker.ReplaceAllString(string(logs), ""), ""), "the log", flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'L':
+			if skipped > 0 {
+				following := stopFollow != nil
+				leave()
+				fetchAndDisplayJobLogs(app, projectID, jobID, true, following, crumbs, views[len(views)-1])
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'f':
+			if stopFollow != nil {
+				stopFollowing()
+			} else if err := startFollowing(); err != nil {
+				showError(app, err.Error(), flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '/':
+			flex.ResizeItem(searchInput, 1, 0)
+			app.SetFocus(searchInput)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'n':
+			showMatch(currentMatch + 1)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'N':
+			showMatch(currentMatch - 1)
+			return nil
+		}
+		return event
+	})
+
+	pushView(app, flex)
+	if follow {
+		if err := startFollowing(); err != nil {
+			showError(app, err.Error(), flex)
+		}
+	}
+}
+
+// fetchJobLog fetches the whole log of a job.
+func fetchJobLog(ctx context.Context, projectID, jobID string) ([]byte, error) {
+	id, err := toInt(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching logs: %w", err)
+	}
+
+	logsReader, _, err := gitlabClient.GetTraceFile(ctx, projectID, id)
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching logs: %w", err)
+	}
+
+	logs, err := io.ReadAll(logsReader)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading logs: %w", err)
+	}
+	return logs, nil
+}
+
+// saveJobLog writes logs to job-<jobID>.log in the working directory and
+// returns the file's path.
+func saveJobLog(jobID string, logs []byte) (string, error) {
+	path, err := filepath.Abs(fmt.Sprintf("job-%s.log", jobID))
+	if err != nil {
+		return "", fmt.Errorf("Error saving log: %w", err)
+	}
+
+	if err := os.WriteFile(path, logs, 0o644); err != nil {
+		return "", fmt.Errorf("Error saving log: %w", err)
+	}
+	return path, nil
+}
+
+const followInterval = 3 * time.Second
+
+// logFollowError logs a failed poll of a followed job, unless it failed for
+// the log being left.
+func logFollowError(ctx context.Context, jobID int, err error) {
+	if ctx.Err() == nil {
+		logError("Error following job %d: %v", jobID, err)
+	}
+}
+
+// followJobTrace polls the status and trace of a job until ctx is canceled or
+// the job reaches a terminal state, handing every snapshot to update on the
+// UI goroutine.
+func followJobTrace(ctx context.Context, app *tview.Application, projectID string, jobID int, update func(trace []byte, finished bool)) {
+	ticker := time.NewTicker(followInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Fetch the status first so the trace read afterwards is complete
+		// once the job is reported as finished.
+		job, _, err := gitlabClient.GetJob(ctx, projectID, jobID)
+		if err != nil {
+			logFollowError(ctx, jobID, err)
+			continue
+		}
+
+		traceReader, _, err := gitlabClient.GetTraceFile(ctx, projectID, jobID)
+		if err != nil {
+			logFollowError(ctx, jobID, err)
+			continue
+		}
+
+		trace, err := io.ReadAll(traceReader)
+		if err != nil {
+			continue
+		}
+
+		finished := isTerminalStatus(job.Status)
+		app.QueueUpdateDraw(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			update(trace, finished)
+		})
+
+		if finished {
+			return
+		}
+	}
+}
+
+// printJobLog prints the log of --job to stdout, for when that isn't a
+// terminal. With follow it keeps printing what the job adds to its log and
+// returns once the job finished; failed polls are then reported on stderr
+// and retried.
+func printJobLog(follow bool) error {
+	gl, err := scriptClient("--job")
+	if err != nil {
+		return err
+	}
+
+	// requestContext limits every request to loadTimeout, so following
+	// isn't limited as a whole.
+	requestContext := func() (context.Context, context.CancelFunc) {
+		if loadTimeout > 0 {
+			return context.WithTimeout(context.Background(), loadTimeout)
+		}
+		return context.WithCancel(context.Background())
+	}
+
+	ctx, cancel := requestContext()
+	project, err := resolveProject(ctx, gl, *projectFlag)
+	cancel()
+	if err != nil {
+		return err
+	}
+	projectID := strconv.Itoa(project.ID)
+
+	// poll prints what was added to the log since the last poll and returns
+	// the status of the job. The status is fetched first so the log read
+	// afterwards is complete once the job is reported as finished.
+	printed := 0
+	poll := func() (string, error) {
+		ctx, cancel := requestContext()
+		defer cancel()
+
+		job, _, err := gl.GetJob(ctx, projectID, *jobFlag)
+		if err != nil {
+			return "", fmt.Errorf("Error fetching job %d: %w", *jobFlag, err)
+		}
+
+		trace, resp, err := gl.GetTraceFile(ctx, projectID, *jobFlag)
+		if err != nil {
+			// Jobs that haven't started have no log yet.
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return job.Status, nil
+			}
+			return "", fmt.Errorf("Error fetching logs: %w", err)
+		}
+
+		logs, err := io.ReadAll(trace)
+		if err != nil {
+			return "", fmt.Errorf("Error reading logs: %w", err)
+		}
+		if len(logs) > printed {
+			if _, err := os.Stdout.Write(logs[printed:]); err != nil {
+				return "", err
+			}
+			printed = len(logs)
+		}
+		return job.Status, nil
+	}
+
+	// Errors before anything was fetched, such as a wrong job ID, aren't
+	// worth retrying.
+	status, err := poll()
+	if err != nil {
+		return err
+	}
+	if !follow {
+		if printed == 0 {
+			fmt.Fprintf(os.Stderr, "Job %d has no log yet. It's %s.\n", *jobFlag, status)
+		}
+		return nil
+	}
+
+	for !isTerminalStatus(status) {
+		time.Sleep(followInterval)
+		polled, err := poll()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			continue
+		}
+		status = polled
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+// logs_test.go
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestReadLogTail(t *testing.T) {
+	tests := []struct {
+		name        string
+		log         string
+		limit       int
+		want        string
+		wantSkipped int
+	}{
+		{name: "no limit", log: "one\ntwo\n", want: "one\ntwo\n"},
+		{name: "under the limit", log: "one\ntwo\n", limit: 100, want: "one\ntwo\n"},
+		{name: "at the limit", log: "one\ntwo\n", limit: 8, want: "one\ntwo\n"},
+		{name: "starts at a line", log: "one\ntwo\nthree\n", limit: 8, want: "three\n", wantSkipped: 8},
+		{name: "limit on a line break", log: "one\ntwo\nthree\n", limit: 10, want: "three\n", wantSkipped: 8},
+		{name: "no line break in the tail", log: "one\nlonglonglong", limit: 4, want: "long", wantSkipped: 12},
+		{name: "empty", log: "", limit: 4, want: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, skipped, err := readLogTail(bytes.NewReader([]byte(tt.log)), tt.limit)
+			if err != nil {
+				t.Fatalf("readLogTail() error = %v", err)
+			}
+			if string(got) != tt.want || skipped != tt.wantSkipped {
+				t.Errorf("readLogTail() = %q, %d, want %q, %d", got, skipped, tt.want, tt.wantSkipped)
+			}
+		})
+	}
+}
@@ -2,8 +2,12 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
 	"fmt"
-	"io"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
@@ -14,336 +18,449 @@ import (
 )
 
 var (
-	gitlabClient   *gitlab.Client
-	token          string
-	gitlabURL      string
-	lastSearchTerm string
+	gitlabClient  GitLab
+	token         string
+	gitlabURL     string
+	activeProfile string
+
+	// currentUser is the username the token of the active profile belongs
+	// to.
+	currentUser string
+
+	// insecureTLS is set while the certificate of the instance of the active
+	// profile isn't checked.
+	insecureTLS bool
 )
 
-func init() {
-	token := os.Getenv("GITLAB_PERSONAL_TOKEN")
-	if token == "" {
-		fmt.Println("Please set GITLAB_PERSONAL_TOKEN environment variable.")
+var (
+	configPath   = flag.String("config", "", "path to config file (default ~/.config/gitlab-pipe-viewer/config.yaml)")
+	profileName  = flag.String("profile", "", "name of the config profile to use, skipping the profile picker")
+	projectFlag  = flag.String("project", "", "ID or path of a project to open right away")
+	branchFlag   = flag.String("branch", "", "with --project, open the pipelines of this branch")
+	pipelineFlag = flag.Int("pipeline", 0, "with --project, open the jobs of this pipeline")
+	jobFlag      = flag.Int("job", 0, "with --project, open the log of this job, or print it when stdout isn't a terminal")
+	followFlag   = flag.Bool("follow", false, "with --job, follow the log until the job finishes")
+	detectFlag   = flag.Bool("detect", false, "open the pipelines of the project and branch of the git repository in the working directory")
+	jsonFlag     = flag.Bool("json", false, "with --project, print the pipelines, or with --pipeline its jobs, as JSON instead of opening them")
+)
+
+// cacheTTL is how long list results are cached. 0 turns caching off.
+var cacheTTL = defaultCacheTTL
+
+// profiles are the instances the user can pick from at startup.
+var profiles []Profile
+
+// startup is the view shown after connecting, see Config.Startup.
+var startup = "menu"
+
+// setup parses the flags and reads the config. It exits on errors.
+func setup() {
+	flag.Parse()
+
+	if *projectFlag == "" && (*branchFlag != "" || *pipelineFlag != 0 || *jobFlag != 0) {
+		fmt.Println("--branch, --pipeline and --job need --project")
 		os.Exit(1)
 	}
-
-	gitlabURL = os.Getenv("GITLAB_URL")
-	if gitlabURL == "" {
-		gitlabURL = "https://gitlab.com"
+	if *followFlag && *jobFlag == 0 {
+		fmt.Println("--follow needs --job")
+		os.Exit(1)
 	}
 
-	// Initialize GitLab client and handle errors
-	var err error
-	gitlabClient, err = gitlab.NewClient(token, gitlab.WithBaseURL(gitlabURL+"/api/v4"))
+	cfg, err := loadConfig(*configPath)
 	if err != nil {
-		fmt.Println("Error creating GitLab client:", err)
+		fmt.Println("Error loading config:", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Connecting to Instance:", gitlabURL)
-
-}
-
-func main() {
-	app := tview.NewApplication()
-
-	modal := tview.NewModal().
-		SetText("Choose an Option").
-		AddButtons([]string{"List all groups", "Search group by name"}).
-		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			switch buttonLabel {
-			case "List all groups":
-				app.SetRoot(buildTree(app, ""), true)
-			case "Search group by name":
-				showGroupSearchInput(app)
-			}
-		})
+	theme, err = cfg.Theme.withDefaults()
+	if err != nil {
+		fmt.Println("Error loading config:", err)
+		os.Exit(1)
+	}
 
-	if err := app.SetRoot(modal, false).Run(); err != nil {
-		fmt.Println("Error:", err)
+	if cfg.CacheTTL != nil {
+		cacheTTL = *cfg.CacheTTL
+	}
+	watchBell = cfg.Bell
+	membershipOnly = cfg.MembershipOnly
+	showArchived = cfg.ShowArchived
+	if cfg.PerPage != nil {
+		perPage = *cfg.PerPage
+	}
+	if cfg.MaxConcurrency != nil {
+		maxConcurrency = *cfg.MaxConcurrency
+	}
+	if cfg.AutoRefresh != nil {
+		autoRefresh = *cfg.AutoRefresh
+	}
+	if cfg.Timeout != nil {
+		loadTimeout = *cfg.Timeout
+	}
+	if cfg.ActivityDays != nil {
+		activityDays = *cfg.ActivityDays
+	}
+	if len(cfg.PipelineColumns) > 0 {
+		pipelineColumns = cfg.PipelineColumns
+	}
+	if cfg.Startup != "" {
+		startup = cfg.Startup
+	}
+	if cfg.ConfirmActions != nil {
+		confirmActions = *cfg.ConfirmActions
+	}
+	if cfg.LogLimitKB != nil {
+		logLimit = *cfg.LogLimitKB * 1024
 	}
-}
 
-func showGroupSearchInput(app *tview.Application) {
-	inputField := tview.NewInputField().
-		SetLabel("Enter Group Name: ")
+	profiles, err = cfg.allProfiles()
+	if err != nil {
+		fmt.Println("Error loading config:", err)
+		os.Exit(1)
+	}
 
-	inputField.SetDoneFunc(func(key tcell.Key) {
-		if key == tcell.KeyEnter {
-			searchTerm := inputField.GetText()
-			lastSearchTerm = searchTerm
-			app.SetRoot(buildTree(app, searchTerm), true)
+	if *profileName != "" {
+		profile, ok := findProfile(profiles, *profileName)
+		if !ok {
+			fmt.Println("Unknown profile:", *profileName)
+			os.Exit(1)
 		}
-	})
+		profiles = []Profile{profile}
+	}
 
-	flex := tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(inputField, 0, 1, true)
+	for _, profile := range profiles {
+		if profile.InsecureSkipVerify {
+			fmt.Fprintf(os.Stderr, "WARNING: TLS certificate verification is disabled for profile %s (%s). Connections to it can be intercepted.\n", profile.Name, profile.URL)
+		}
+	}
 
-	app.SetRoot(flex, true).SetFocus(inputField)
-}
+	if len(profiles) == 0 {
+		fmt.Println("Please set GITLAB_PERSONAL_TOKEN environment variable or token in the config file.")
+		os.Exit(1)
+	}
 
-func buildTree(app *tview.Application, searchTerm string) *tview.TreeView {
-	root := tview.NewTreeNode("GitLab Pipelines").
-		SetColor(tcell.ColorYellow).
-		SetSelectable(false)
-
-	tree := tview.NewTreeView().
-		SetRoot(root).
-		SetCurrentNode(root).
-		SetTopLevel(1).
-		SetGraphicsColor(tcell.ColorOrange)
-
-	tree.SetSelectedFunc(func(node *tview.TreeNode) {
-		projectName := node.GetText()
-		if strings.HasPrefix(projectName, "Project: ") {
-			showPipelines(app, node)
+	// Without a detected project the start menu is shown as usual. The
+	// project is looked for on the instance its remote is on.
+	if *detectFlag && *projectFlag == "" {
+		if project, branch, profile, ok := detectProject(profiles); ok {
+			*projectFlag = project
+			*branchFlag = branch
+			profiles = []Profile{profile}
 		}
-	})
-
-	root.AddChild(buildGroups(searchTerm))
+	}
 
-	return tree
+	if *jsonFlag && *projectFlag == "" {
+		fmt.Println("--json needs --project, or --detect inside a clone")
+		os.Exit(1)
+	}
 }
 
-func buildGroups(searchTerm string) *tview.TreeNode {
-	root := tview.NewTreeNode("󰮠 Instance: " + gitlabURL).
-		SetColor(tcell.ColorOrangeRed)
+func main() {
+	setup()
 
-	var allGroups []*gitlab.Group
-	listOptions := &gitlab.ListGroupsOptions{
-		ListOptions: gitlab.ListOptions{
-			PerPage: 100,
-			Page:    1,
-		},
+	if *jsonFlag {
+		if err := printJSON(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	for {
-		groups, resp, err := gitlabClient.Groups.ListGroups(listOptions)
-		if err != nil {
-			fmt.Println("Error fetching groups:", err)
-			return root
+	// Piped into another program, the log is printed rather than shown.
+	if *jobFlag != 0 && !isTerminal(os.Stdout) {
+		if err := printJobLog(*followFlag); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
 		}
+		return
+	}
 
-		allGroups = append(allGroups, groups...)
+	app := tview.NewApplication().EnableMouse(true)
 
-		if resp.CurrentPage >= resp.TotalPages {
-			break
-		}
-		listOptions.Page = resp.NextPage
-	}
+	options := []string{"List all groups", "Search group by name", "Recent pipelines"}
 
-	for _, group := range allGroups {
-		if searchTerm == "" || strings.Contains(strings.ToLower(group.Name), strings.ToLower(searchTerm)) {
-			groupNode := tview.NewTreeNode(" Group: " + group.Name).
-				SetColor(tcell.ColorWhiteSmoke)
-			root.AddChild(groupNode)
+	// A broken state file only loses the resume option.
+	state, _ := loadState()
+	if state.canResume() {
+		options = append(options, "Resume where you left off")
+	}
 
-			projects, _, err := gitlabClient.Groups.ListGroupProjects(group.ID, &gitlab.ListGroupProjectsOptions{})
-			if err != nil {
-				fmt.Println("Error fetching projects for group", group.Name, ":", err)
-				continue
-			}
+	modal := tview.NewModal().
+		SetText("Choose an Option").
+		AddButtons(options)
+
+	modal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		switch buttonLabel {
+		case "Resume where you left off":
+			resume(app, state, modal)
+		case "List all groups":
+			showTree(app, "")
+		case "Search group by name":
+			showGroupSearchInput(app)
+		case "Recent pipelines":
+			showDashboard(app, modal)
+		}
+	})
 
-			for _, project := range projects {
-				projectNode := tview.NewTreeNode("Project: " + project.Name).
-					SetColor(tcell.ColorDarkGrey).
-					SetReference(fmt.Sprintf("%d", project.ID))
-				groupNode.AddChild(projectNode)
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if eventLogInput(app, event) {
+			return nil
+		}
+		switch {
+		case event.Key() == tcell.KeyCtrlC:
+			app.Stop()
+			return nil
+		case event.Key() == tcell.KeyCtrlB:
+			showCrumbs(app)
+			return nil
+		case event.Key() == tcell.KeyCtrlL:
+			toggleEventLog(app)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'q':
+			// Let input fields receive a literal q.
+			if _, ok := app.GetFocus().(*tview.InputField); ok {
+				return event
 			}
+			app.Stop()
+			return nil
 		}
-	}
+		return event
+	})
 
-	return root
-}
+	// The menu stays below views opened from flags or on startup, so Esc
+	// leads back to it.
+	start := func() {
+		pushView(app, modal)
+		if *projectFlag != "" {
+			openFromFlags(app, modal)
+			return
+		}
 
-func showPipelines(app *tview.Application, projectNode *tview.TreeNode) {
-	projectID, ok := projectNode.GetReference().(string)
-	if !ok {
-		fmt.Println("Invalid project reference")
-		return
+		switch startup {
+		case "groups":
+			showTree(app, "")
+		case "search":
+			showGroupSearchInput(app)
+		case "last":
+			// Without saved state there is nothing to resume.
+			if state.canResume() {
+				resume(app, state, modal)
+			}
+		}
 	}
 
-	branches, _, err := gitlabClient.Branches.ListBranches(projectID, &gitlab.ListBranchesOptions{})
-	if err != nil {
-		fmt.Println("Error fetching branches for project", projectID, ":", err)
-		return
+	// With a single candidate there is nothing to pick, so it's connected
+	// to right away. The picker is still there to retry after a failure.
+	picker := buildProfilePicker(app, start)
+	app.SetRoot(picker, false)
+	if len(profiles) == 1 {
+		connect(app, profiles[0], picker, start)
 	}
 
-	dropDown := tview.NewDropDown().
-		SetLabel("Select branch: ").
-		SetFieldBackgroundColor(tcell.ColorDarkGray).
-		SetFieldTextColor(tcell.ColorOrangeRed)
-	for _, branch := range branches {
-		dropDown.AddOption(branch.Name, nil)
+	if err := app.Run(); err != nil {
+		fmt.Println("Error:", err)
 	}
+}
 
-	handleBranchSelection := func(option string, optionIndex int) {
-		selectedBranch := branches[optionIndex].Name
-		fetchAndShowPipelines(app, projectID, selectedBranch)
+// buildProfilePicker returns a modal listing all profiles. Picking one
+// connects to its instance and calls done.
+func buildProfilePicker(app *tview.Application, done func()) *tview.Modal {
+	names := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		names = append(names, profile.Name)
 	}
 
-	dropDown.SetSelectedFunc(handleBranchSelection)
+	picker := tview.NewModal().
+		SetText("Choose a GitLab instance").
+		AddButtons(names)
 
-	flex := tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(tview.NewBox().SetBorder(false).SetBackgroundColor(tcell.ColorDefault), 0, 1, false).
-		AddItem(dropDown, 0, 1, true).
-		AddItem(tview.NewBox().SetBorder(false).SetBackgroundColor(tcell.ColorDefault), 0, 1, false)
-
-	app.SetRoot(flex, true).SetFocus(dropDown)
-}
+	picker.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		if buttonIndex < 0 {
+			return
+		}
 
-func fetchAndShowPipelines(app *tview.Application, projectID, branch string) {
-	projectPipelines, _, err := gitlabClient.Pipelines.ListProjectPipelines(projectID, &gitlab.ListProjectPipelinesOptions{
-		Ref: &branch,
+		connect(app, profiles[buttonIndex], picker, done)
 	})
-	if err != nil {
-		fmt.Println("Error fetching pipelines for project", projectID, "and branch", branch, ":", err)
-		return
-	}
-
-	pipelineList := tview.NewList().ShowSecondaryText(false)
 
-	for _, pipeline := range projectPipelines {
-		pipelineInfo := fmt.Sprintf("Pipeline ID: %d \nStatus: %s \nRef: %s \nSource: %s \nUpdated At: %s \n",
-			pipeline.ID, pipeline.Status, pipeline.Ref, pipeline.Source, pipeline.UpdatedAt.Format("2006-01-02 15:04:05"))
-
-		pipelineList.AddItem(pipelineInfo, "", 0, func() {
-			fetchAndShowJobs(app, projectID, fmt.Sprintf("%d", pipeline.ID), branch)
-		})
-	}
+	return picker
+}
 
-	pipelineList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEsc {
-			app.SetRoot(buildTree(app, lastSearchTerm), true)
-			return nil
+// connect replaces the global GitLab client with one for profile and calls
+// done, once the token is confirmed to work by fetching the user it belongs
+// to. Failures are shown over returnTo and keep the previous client.
+func connect(app *tview.Application, profile Profile, returnTo tview.Primitive, done func()) {
+	var gl GitLab
+	var user *gitlab.User
+	loadAsync(app, "Connecting to "+profile.URL+"...", returnTo, func(ctx context.Context) error {
+		var err error
+		gl, err = newProfileClient(profile)
+		if err != nil {
+			return fmt.Errorf("Error creating GitLab client: %w", err)
 		}
-		return event
-	})
 
-	flex := tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(pipelineList, 0, 1, true).
-		AddItem(tview.NewButton("ESC - Back").SetSelectedFunc(func() {
-			app.SetRoot(buildTree(app, ""), true)
-		}), 1, 0, false)
+		var resp *gitlab.Response
+		user, resp, err = gl.CurrentUser(ctx)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+				return fmt.Errorf("Authentication failed for %s: the token of profile %s is invalid or expired", profile.URL, profile.Name)
+			}
+			return fmt.Errorf("Error connecting to %s: %w", profile.URL, err)
+		}
+		return nil
+	}, func(err error) {
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
 
-	app.SetRoot(flex, true).SetFocus(pipelineList)
+		gitlabClient = gl
+		gitlabURL = profile.URL
+		activeProfile = profile.Name
+		currentUser = user.Username
+		insecureTLS = profile.InsecureSkipVerify
+		done()
+	})
 }
 
-func fetchAndShowJobs(app *tview.Application, projectID, pipelineID, pipelineName string) {
-	pipelineJobs, _, err := gitlabClient.Jobs.ListPipelineJobs(projectID, toInt(pipelineID), &gitlab.ListJobsOptions{})
+// newProfileClient returns a GitLab client for the instance of profile.
+func newProfileClient(profile Profile) (GitLab, error) {
+	httpClient, err := newHTTPClient(profile)
 	if err != nil {
-		fmt.Println("Error fetching jobs for project", projectID, "and pipeline", pipelineID, ":", err)
-		return
+		return nil, err
 	}
 
-	app.SetRoot(rebuildJobListView(app, pipelineJobs, projectID, pipelineName), true)
-}
-
-func rebuildJobListView(app *tview.Application, pipelineJobs []*gitlab.Job, projectID, pipelineName string) *tview.Flex {
-	jobList := tview.NewList().ShowSecondaryText(false)
+	client, err := gitlab.NewClient(profile.Token,
+		gitlab.WithBaseURL(profile.URL+"/api/v4"),
+		gitlab.WithHTTPClient(httpClient),
+		// Rate limited requests are retried by the adapter, which lets the
+		// user know about the wait.
+		gitlab.WithCustomRetry(func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+			if ctx.Err() != nil {
+				return false, ctx.Err()
+			}
+			if err != nil {
+				return false, err
+			}
+			return resp.StatusCode >= http.StatusInternalServerError, nil
+		}),
+	)
+	if err != nil {
+		return nil, err
+	}
 
-	for _, job := range pipelineJobs {
-		jobInfo := fmt.Sprintf("Job ID: %d \nName: %s \nStatus: %s", job.ID, job.Name, job.Status)
-		jobList.AddItem(jobInfo, "", 0, nil)
+	gl := newGitLab(client)
+	if cacheTTL > 0 {
+		gl = newCachingGitLab(gl, cacheTTL)
 	}
+	return gl, nil
+}
 
-	jobList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
-		selectedJob := pipelineJobs[index]
+// newHTTPClient returns the HTTP client for the instance of profile, with the
+// TLS settings of the profile.
+func newHTTPClient(profile Profile) (*http.Client, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: profile.InsecureSkipVerify,
+	}
 
-		jobActionModal := tview.NewModal().
-			SetText(fmt.Sprintf("Select Action for Job %d", selectedJob.ID)).
-			AddButtons([]string{"Logs", "Retry", "Cancel"})
+	if profile.CACertFile != "" {
+		pem, err := os.ReadFile(profile.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA certificate: %w", err)
+		}
 
-		returnToJobList := func() {
-			app.SetRoot(rebuildJobListView(app, pipelineJobs, projectID, pipelineName), true)
+		// Without the system's CAs only the given one is trusted.
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
 		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", profile.CACertFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
 
-		jobActionModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-			switch buttonLabel {
-			case "Logs":
-				fetchAndDisplayJobLogs(app, projectID, strconv.Itoa(selectedJob.ID), returnToJobList)
-			case "Retry":
-				retryJob(app, projectID, strconv.Itoa(selectedJob.ID))
-				returnToJobList()
-			case "Cancel":
-				returnToJobList()
-			}
-		})
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
+}
 
-		app.SetRoot(jobActionModal, false).SetFocus(jobActionModal)
-	})
+// openFromFlags opens the view selected by --project, --branch and
+// --pipeline.
+func openFromFlags(app *tview.Application, returnTo tview.Primitive) {
+	var project *gitlab.Project
+	loadAsync(app, "Loading project...", returnTo, func(ctx context.Context) (err error) {
+		project, err = resolveProject(ctx, gitlabClient, *projectFlag)
+		return err
+	}, func(err error) {
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
 
-	jobList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEsc {
-			fetchAndShowPipelines(app, projectID, pipelineName)
-			return nil
+		projectID := strconv.Itoa(project.ID)
+		crumbs := projectCrumbs(project)
+
+		switch {
+		case *jobFlag != 0:
+			jobID := strconv.Itoa(*jobFlag)
+			fetchAndDisplayJobLogs(app, projectID, jobID, false, *followFlag, withCrumb(crumbs, "job "+jobID), returnTo)
+		case *pipelineFlag != 0:
+			fetchAndShowStages(app, projectID, strconv.Itoa(*pipelineFlag), *branchFlag, crumbs, returnTo)
+		case *branchFlag != "":
+			fetchAndShowPipelines(app, projectID, *branchFlag, crumbs, returnTo)
+		default:
+			fetchAndShowBranches(app, projectID, crumbs, returnTo, nil)
 		}
-		return event
 	})
-
-	flex := tview.NewFlex().
-		SetDirection(tview.FlexRow).
-		AddItem(jobList, 0, 1, true).
-		AddItem(tview.NewButton("ESC - Back").SetSelectedFunc(func() {
-			fetchAndShowPipelines(app, projectID, pipelineName)
-		}), 1, 0, false)
-
-	return flex
 }
 
-func toInt(s string) int {
-	i, err := strconv.Atoi(s)
-	if err != nil {
-		return 0
-	}
-	return i
+// isTerminal reports whether f is a terminal rather than, say, a pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
 }
 
-func fetchAndDisplayJobLogs(app *tview.Application, projectID, jobID string, returnToModal func()) {
-	logsReader, _, err := gitlabClient.Jobs.GetTraceFile(projectID, toInt(jobID))
-	if err != nil {
-		fmt.Println("Error fetching logs:", err)
-		return
+// resolveProject returns the project with the given numeric ID or path, such
+// as "mygroup/subgroup/app".
+func resolveProject(ctx context.Context, gl GitLab, project string) (*gitlab.Project, error) {
+	// The API takes both forms; paths are URL-encoded by go-gitlab.
+	var pid interface{} = project
+	if id, err := strconv.Atoi(project); err == nil {
+		pid = id
 	}
 
-	logs, err := io.ReadAll(logsReader)
+	p, _, err := gl.GetProject(ctx, pid)
 	if err != nil {
-		fmt.Println("Error reading logs:", err)
-		return
+		return nil, fmt.Errorf("Error fetching project %s: %w", project, err)
 	}
+	return p, nil
+}
 
-	logView := tview.NewTextView().
-		SetText(string(logs)).
-		SetScrollable(true).
-		SetDynamicColors(true).
-		SetRegions(true).
-		SetWordWrap(true)
+// projectCrumbs returns the breadcrumb of a project: the instance, the groups
+// it's in and its name, as if it was reached through the group tree.
+func projectCrumbs(project *gitlab.Project) []string {
+	crumbs := []string{instanceCrumb()}
+	if project.Namespace != nil && project.Namespace.FullPath != "" {
+		crumbs = append(crumbs, strings.Split(project.Namespace.FullPath, "/")...)
+	}
+	return append(crumbs, project.Name)
+}
 
-	logView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Key() == tcell.KeyEsc {
-			returnToModal()
-			return nil
-		}
-		return event
-	})
+func showGroupSearchInput(app *tview.Application) {
+	inputField := tview.NewInputField().
+		SetLabel("Enter Group Name: ")
 
 	flex := tview.NewFlex().
 		SetDirection(tview.FlexRow).
-		AddItem(logView, 0, 1, true).
-		AddItem(tview.NewButton("ESC - Back").SetSelectedFunc(returnToModal), 1, 0, false)
-
-	app.SetRoot(flex, true).SetFocus(flex)
-}
+		AddItem(inputField, 0, 1, true)
 
-func retryJob(app *tview.Application, projectID, jobID string) {
-	_, _, err := gitlabClient.Jobs.RetryJob(projectID, toInt(jobID))
-	if err != nil {
-		fmt.Println("Error retrying job:", err)
-		return
-	}
+	inputField.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			showTree(app, inputField.GetText())
+		case tcell.KeyEsc:
+			popView(app)
+		}
+	})
 
-	fmt.Println("Job retried successfully")
+	pushView(app, flex)
 }
@@ -0,0 +1,1364 @@
+// pipelines.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// showPipelines lets the user pick a branch of the project of projectNode and
+// then lists its pipelines. crumbs is the breadcrumb of the project. The node
+// is marked if the user has no access to the branches.
+func showPipelines(app *tview.Application, projectNode *tview.TreeNode, crumbs []string, returnTo tview.Primitive) {
+	ref, ok := projectNode.GetReference().(*projectRef)
+	if !ok {
+		showError(app, "Invalid project reference", returnTo)
+		return
+	}
+
+	fetchAndShowBranches(app, ref.id, crumbs, returnTo, func() {
+		markNoAccess(projectNode)
+	})
+}
+
+// fetchAndShowBranches lets the user pick a branch of a project and then
+// lists its pipelines. crumbs is the breadcrumb of the project. If GitLab
+// denies access to the branches, denied is called, if set.
+func fetchAndShowBranches(app *tview.Application, projectID string, crumbs []string, returnTo tview.Primitive, denied func()) {
+	var branches []*gitlab.Branch
+	var defaultBranch string
+	loadAsync(app, "Loading branches...", returnTo, func(ctx context.Context) (err error) {
+		branches, err = listBranches(ctx, gitlabClient, projectID)
+		if err != nil {
+			return err
+		}
+
+		// Without the default branch nothing is preselected, which is no
+		// reason to fail.
+		if project, _, err := gitlabClient.GetProject(ctx, projectID); err == nil {
+			defaultBranch = project.DefaultBranch
+		}
+		return nil
+	}, func(err error) {
+		if isAccessDenied(err) {
+			if denied != nil {
+				denied()
+			}
+			showMessage(app, fmt.Sprintf("You don't have access to the branches of project %s.", projectID), returnTo)
+			return
+		}
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
+
+		if len(branches) == 0 {
+			showMessage(app, "No branches found.", returnTo)
+			return
+		}
+
+		showBranchDropDown(app, projectID, branches, defaultBranch, crumbs)
+	})
+}
+
+func listBranches(ctx context.Context, gl GitLab, projectID string) ([]*gitlab.Branch, error) {
+	var branches []*gitlab.Branch
+	listOptions := &gitlab.ListBranchesOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: perPage,
+			Page:    1,
+		},
+	}
+
+	for {
+		page, resp, err := gl.ListBranches(ctx, projectID, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching branches for project %s: %w", projectID, err)
+		}
+
+		branches = append(branches, page...)
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+
+	return branches, nil
+}
+
+// showBranchDropDown lets the user pick a branch, or with Tab one of the open
+// merge requests, and shows its pipelines. defaultBranch, if any, comes first
+// and is preselected, so Enter picks it right away. / searches the branches
+// instead, which is faster with many of them.
+func showBranchDropDown(app *tview.Application, projectID string, branches []*gitlab.Branch, defaultBranch string, crumbs []string) {
+	dropDown := tview.NewDropDown().
+		SetFieldBackgroundColor(themeColor(theme.FieldBackground)).
+		SetFieldTextColor(themeColor(theme.Field))
+
+	branches = defaultBranchFirst(branches, defaultBranch)
+
+	// The first option lists the pipelines of all branches.
+	branchOptions := []string{"All branches"}
+	for _, branch := range branches {
+		branchOptions = append(branchOptions, branch.Name)
+	}
+
+	flex := tview.NewFlex()
+
+	// selectBranch shows the pipelines of the branch option optionIndex
+	// stands for, restoring returnTo if the load is canceled.
+	selectBranch := func(optionIndex int, returnTo tview.Primitive) {
+		if optionIndex < 0 || optionIndex > len(branches) {
+			return
+		}
+
+		selectedBranch := ""
+		if optionIndex > 0 {
+			selectedBranch = branches[optionIndex-1].Name
+		}
+
+		// Not being able to save where the user is shouldn't keep them from
+		// getting there.
+		_ = saveLocation(crumbs[1:len(crumbs)-1], projectID, crumbs[len(crumbs)-1], selectedBranch)
+		fetchAndShowPipelines(app, projectID, selectedBranch, crumbs, returnTo)
+	}
+
+	handleBranchSelection := func(option string, optionIndex int) {
+		selectBranch(optionIndex, flex)
+	}
+
+	showBranches := func() {
+		// Setting the current option calls the selected func, so that's
+		// only set afterwards.
+		current := -1
+		if len(branches) > 0 && branches[0].Name == defaultBranch {
+			current = 1
+		}
+		dropDown.SetLabel("Select branch (Space: pick, /: search, Tab: merge requests, e: environments): ").
+			SetOptions(branchOptions, nil).
+			SetCurrentOption(current).
+			SetSelectedFunc(handleBranchSelection)
+	}
+
+	// Merge requests are only fetched the first time they're asked for.
+	var mergeRequests []*gitlab.MergeRequest
+	mergeRequestsLoaded := false
+	showingMergeRequests := false
+
+	handleMergeRequestSelection := func(option string, optionIndex int) {
+		if optionIndex < 0 || optionIndex >= len(mergeRequests) {
+			return
+		}
+
+		fetchAndShowMergeRequestPipelines(app, projectID, mergeRequests[optionIndex], crumbs, flex)
+	}
+
+	showMergeRequests := func() {
+		options := make([]string, 0, len(mergeRequests))
+		for _, mergeRequest := range mergeRequests {
+			options = append(options, fmt.Sprintf("!%d %s", mergeRequest.IID, mergeRequest.Title))
+		}
+
+		dropDown.SetLabel("Select merge request (Tab: branches, e: environments): ").
+			SetOptions(options, handleMergeRequestSelection).
+			SetCurrentOption(-1)
+	}
+
+	toggle := func() {
+		switch {
+		case showingMergeRequests:
+			showingMergeRequests = false
+			showBranches()
+		case mergeRequestsLoaded:
+			showingMergeRequests = true
+			showMergeRequests()
+		default:
+			loadAsync(app, "Loading merge requests...", flex, func(ctx context.Context) (err error) {
+				mergeRequests, err = listMergeRequests(ctx, gitlabClient, projectID)
+				return err
+			}, func(err error) {
+				if err != nil {
+					showError(app, err.Error(), flex)
+					return
+				}
+
+				app.SetRoot(flex, true)
+				if len(mergeRequests) == 0 {
+					showMessage(app, "No open merge requests found.", flex)
+					return
+				}
+
+				mergeRequestsLoaded = true
+				showingMergeRequests = true
+				showMergeRequests()
+			})
+		}
+	}
+
+	showBranches()
+	dropDown.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if dropDown.IsOpen() {
+			return event
+		}
+
+		switch {
+		case event.Key() == tcell.KeyRune && event.Rune() == 'e':
+			fetchAndShowEnvironments(app, projectID, crumbs, flex)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '/' && !showingMergeRequests:
+			showBranchSearch(app, branchOptions, crumbs, selectBranch)
+			return nil
+		case event.Key() == tcell.KeyEnter && !showingMergeRequests:
+			// Enter takes the preselected branch; Space opens the list.
+			if index, option := dropDown.GetCurrentOption(); index >= 0 {
+				handleBranchSelection(option, index)
+				return nil
+			}
+		}
+		return event
+	})
+	dropDown.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEsc:
+			popView(app)
+		case tcell.KeyTab, tcell.KeyBacktab:
+			toggle()
+		}
+	})
+
+	flex.
+		SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, crumbs), 1, 0, false).
+		AddItem(tview.NewBox().SetBorder(false).SetBackgroundColor(tcell.ColorDefault), 0, 1, false).
+		AddItem(dropDown, 0, 1, true).
+		AddItem(tview.NewBox().SetBorder(false).SetBackgroundColor(tcell.ColorDefault), 0, 1, false)
+
+	pushView(app, flex)
+}
+
+// showBranchSearch lists the branch options, narrowed down to the ones
+// containing what's typed. Up and Down move through them and Enter picks the
+// selected one, calling selected with its index in options and the view to
+// return to.
+func showBranchSearch(app *tview.Application, options []string, crumbs []string, selected func(optionIndex int, returnTo tview.Primitive)) {
+	searchInput := tview.NewInputField().
+		SetLabel("Search branches: ")
+
+	branchList := tview.NewList().ShowSecondaryText(false)
+	branchList.SetBorder(true)
+
+	// shown are the indexes in options of the listed branches.
+	var shown []int
+
+	render := func(term string) {
+		branchList.Clear()
+		shown = nil
+		for i, option := range options {
+			if matchesName(option, term) {
+				shown = append(shown, i)
+				branchList.AddItem(tview.Escape(option), "", 0, nil)
+			}
+		}
+		branchList.SetTitle(fmt.Sprintf(" Branches (%d of %d) ", len(shown), len(options)))
+	}
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, withCrumb(crumbs, "branches")), 1, 0, false).
+		AddItem(searchInput, 1, 0, true).
+		AddItem(branchList, 0, 1, false).
+		AddItem(buildFooter(app, func() {
+			popView(app)
+		}), 1, 0, false)
+
+	searchInput.SetChangedFunc(render)
+
+	searchInput.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// The list never gets the focus, the input keeps taking the typing.
+		current := branchList.GetCurrentItem()
+		switch event.Key() {
+		case tcell.KeyUp:
+			if current > 0 {
+				branchList.SetCurrentItem(current - 1)
+			}
+			return nil
+		case tcell.KeyDown:
+			if current < branchList.GetItemCount()-1 {
+				branchList.SetCurrentItem(current + 1)
+			}
+			return nil
+		}
+		return event
+	})
+
+	searchInput.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEsc:
+			popView(app)
+		case tcell.KeyEnter:
+			if len(shown) > 0 {
+				selected(shown[branchList.GetCurrentItem()], flex)
+			}
+		}
+	})
+
+	render("")
+	pushView(app, flex)
+}
+
+// defaultBranchFirst returns branches with the branch named defaultBranch
+// moved to the front.
+func defaultBranchFirst(branches []*gitlab.Branch, defaultBranch string) []*gitlab.Branch {
+	for i, branch := range branches {
+		if branch.Name == defaultBranch {
+			ordered := append([]*gitlab.Branch{branch}, branches[:i]...)
+			return append(ordered, branches[i+1:]...)
+		}
+	}
+	return branches
+}
+
+// listMergeRequests returns the open merge requests of a project.
+func listMergeRequests(ctx context.Context, gl GitLab, projectID string) ([]*gitlab.MergeRequest, error) {
+	var mergeRequests []*gitlab.MergeRequest
+	listOptions := &gitlab.ListProjectMergeRequestsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: perPage,
+			Page:    1,
+		},
+		State: gitlab.String("opened"),
+	}
+
+	for {
+		page, resp, err := gl.ListProjectMergeRequests(ctx, projectID, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching merge requests for project %s: %w", projectID, err)
+		}
+
+		mergeRequests = append(mergeRequests, page...)
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+
+	return mergeRequests, nil
+}
+
+// pipelineKeys are the shortcuts of the pipeline list.
+var pipelineKeys = []keyHelp{
+	{"Enter", "Show stages"},
+	{"a", "Pipeline actions"},
+	{"Space", "Mark for comparison"},
+	{"c", "Compare the two marked pipelines"},
+	{"n", "Run a new pipeline"},
+	{"s", "Cycle status filter"},
+	{"S", "Cycle source filter"},
+	{"/", "Filter by status, ref or source"},
+	{"o", "Cycle sort order"},
+	{"R", "Toggle auto-refresh"},
+	{"y", "Copy web URL"},
+	{"Y", "Copy a glab or curl command"},
+	{"b", "Open in browser"},
+	{"r", "Refresh"},
+	{"Esc", "Back"},
+}
+
+// defaultAutoRefresh is how often R refreshes a pipeline list when no
+// interval is configured.
+const defaultAutoRefresh = 30 * time.Second
+
+// autoRefresh is how often pipeline lists refresh themselves from the
+// start. 0 leaves auto-refresh off until R turns it on.
+var autoRefresh time.Duration
+
+// pipelineStatusFilters are the statuses the pipeline list cycles through
+// with s. The empty status shows all pipelines.
+var pipelineStatusFilters = []gitlab.BuildStateValue{"", gitlab.Failed, gitlab.Success, gitlab.Running}
+
+// pipelineSourceFilters are the sources the pipeline list cycles through
+// with S. The empty source shows all pipelines.
+var pipelineSourceFilters = []string{"", "push", "web", "schedule", "merge_request_event", "trigger", "api", "parent_pipeline", "pipeline"}
+
+// pipelineSourceLabels are short labels for the sources of pipelines, i.e.
+// what triggered them.
+var pipelineSourceLabels = map[string]string{
+	"push":                          "push",
+	"web":                           "run from web UI",
+	"schedule":                      "scheduled",
+	"merge_request_event":           "merge request",
+	"trigger":                       "trigger token",
+	"api":                           "API",
+	"parent_pipeline":               "child pipeline",
+	"pipeline":                      "multi-project",
+	"external":                      "external CI",
+	"external_pull_request_event":   "external pull request",
+	"chat":                          "ChatOps",
+	"webide":                        "Web IDE",
+	"security_orchestration_policy": "security policy",
+	"ondemand_dast_scan":            "DAST scan",
+}
+
+// sourceLabel returns how the source of a pipeline is shown. Unknown sources
+// are shown as they are.
+func sourceLabel(source string) string {
+	if label, ok := pipelineSourceLabels[source]; ok {
+		return label
+	}
+	return source
+}
+
+// pipelineColumnLabels are the fields of a pipeline the pipeline list can
+// show, by the names they're configured with, and what they're labeled.
+var pipelineColumnLabels = map[string]string{
+	"id":       "Pipeline ID",
+	"status":   "Status",
+	"ref":      "Ref",
+	"source":   "Source",
+	"user":     "Triggered by",
+	"jobs":     "Jobs",
+	"updated":  "Updated At",
+	"duration": "Duration",
+	"sha":      "Commit",
+}
+
+// defaultPipelineColumns are the fields the pipeline list shows when no
+// pipeline_columns are configured.
+var defaultPipelineColumns = []string{"id", "status", "ref", "source", "user", "jobs", "updated"}
+
+// pipelineColumns are the fields of every pipeline in pipeline lists, in the
+// order they're shown.
+var pipelineColumns = defaultPipelineColumns
+
+// pipelineOrder is a sort order of the pipeline list, in terms of the API's
+// order_by and sort parameters.
+type pipelineOrder struct {
+	name    string
+	orderBy string
+	sort    string
+}
+
+// pipelineOrders are the orders the pipeline list cycles through with o.
+var pipelineOrders = []pipelineOrder{
+	{"newest", "id", "desc"},
+	{"oldest", "id", "asc"},
+	{"status", "status", "asc"},
+}
+
+// pipelineListTitle returns the title of a pipeline list, naming the query
+// and text filter in effect. count is the number of pipelines shown; more
+// tells there are further pages.
+func pipelineListTitle(label string, count int, more bool, query pipelineQuery, filter string) string {
+	status := query.status
+	if status == "" {
+		status = "all"
+	}
+	source := "all"
+	if query.source != "" {
+		source = sourceLabel(query.source)
+	}
+	shown := fmt.Sprintf("%d", count)
+	if more {
+		shown += "+"
+	}
+	title := fmt.Sprintf(" Pipelines for %s (%s) - status: %s, source: %s, order: %s", label, shown, status, source, query.order.name)
+	if filter != "" {
+		title += fmt.Sprintf(", filter: %q", filter)
+	}
+	return tview.Escape(title) + " (s/S/o to change) "
+}
+
+// matchesPipeline reports whether the status, ref or source of pipeline
+// contains filter, ignoring case.
+func matchesPipeline(pipeline *gitlab.PipelineInfo, filter string) bool {
+	return matchesName(pipeline.Status, filter) || matchesName(pipeline.Ref, filter) ||
+		matchesName(pipeline.Source, filter) || matchesName(sourceLabel(pipeline.Source), filter)
+}
+
+// branchLabel returns how branch is shown; the empty branch stands for all
+// branches.
+func branchLabel(branch string) string {
+	if branch == "" {
+		return "all branches"
+	}
+	return branch
+}
+
+// pipelineQuery selects which pipelines of a pipeline list are fetched and
+// how they're sorted. An empty status or source selects all of them.
+type pipelineQuery struct {
+	page   int
+	status gitlab.BuildStateValue
+	source string
+	order  pipelineOrder
+}
+
+// listPipelinesFunc fetches the pipelines of a pipeline list selected by
+// query.
+type listPipelinesFunc func(ctx context.Context, query pipelineQuery) ([]*gitlab.PipelineInfo, *gitlab.Response, error)
+
+// fetchAndShowPipelines lists the pipelines of a branch, or of all branches
+// if branch is empty. crumbs is the breadcrumb of the project.
+func fetchAndShowPipelines(app *tview.Application, projectID, branch string, crumbs []string, returnTo tview.Primitive) {
+	list := func(ctx context.Context, query pipelineQuery) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+		return listBranchPipelines(ctx, gitlabClient, projectID, branch, query)
+	}
+
+	showPipelineList(app, projectID, branch, branchLabel(branch), list, crumbs, returnTo)
+}
+
+// showLatestPipeline lists the jobs of the latest pipeline on the default
+// branch of a project. crumbs is the breadcrumb of the project.
+func showLatestPipeline(app *tview.Application, projectID string, crumbs []string, returnTo tview.Primitive) {
+	var (
+		pipeline *gitlab.PipelineInfo
+		branch   string
+		jobs     []*gitlab.Job
+	)
+	loadAsync(app, "Loading latest pipeline...", returnTo, func(ctx context.Context) error {
+		project, _, err := gitlabClient.GetProject(ctx, projectID)
+		if err != nil {
+			return fmt.Errorf("Error fetching project %s: %w", projectID, err)
+		}
+		branch = project.DefaultBranch
+
+		pipelines, _, err := listBranchPipelines(ctx, gitlabClient, projectID, branch, pipelineQuery{page: 1, order: pipelineOrders[0]})
+		if err != nil {
+			return fmt.Errorf("Error fetching pipelines for project %s on %s: %w", projectID, branch, err)
+		}
+		if len(pipelines) == 0 {
+			return nil
+		}
+		pipeline = pipelines[0]
+
+		jobs, err = listPipelineJobs(ctx, gitlabClient, projectID, fmt.Sprintf("%d", pipeline.ID))
+		return err
+	}, func(err error) {
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
+
+		if pipeline == nil {
+			showMessage(app, "No pipelines on "+branchLabel(branch)+".", returnTo)
+			return
+		}
+
+		pushView(app, rebuildJobListView(app, jobs, projectID, fmt.Sprintf("%d", pipeline.ID), pipeline.Ref, "", crumbs))
+	})
+}
+
+// listBranchPipelines returns a page of the pipelines of a project on branch,
+// or on all branches if branch is empty.
+func listBranchPipelines(ctx context.Context, gl GitLab, projectID, branch string, query pipelineQuery) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+	options := &gitlab.ListProjectPipelinesOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: 20,
+			Page:    query.page,
+		},
+		OrderBy: &query.order.orderBy,
+		Sort:    &query.order.sort,
+	}
+	if branch != "" {
+		options.Ref = &branch
+	}
+	if query.status != "" {
+		options.Status = &query.status
+	}
+	if query.source != "" {
+		options.Source = &query.source
+	}
+	return gl.ListProjectPipelines(ctx, projectID, options)
+}
+
+// fetchAndShowMergeRequestPipelines lists the pipelines of a merge request.
+// crumbs is the breadcrumb of the project.
+func fetchAndShowMergeRequestPipelines(app *tview.Application, projectID string, mergeRequest *gitlab.MergeRequest, crumbs []string, returnTo tview.Primitive) {
+	list := func(ctx context.Context, query pipelineQuery) ([]*gitlab.PipelineInfo, *gitlab.Response, error) {
+		pipelines, resp, err := gitlabClient.ListMergeRequestPipelines(ctx, projectID, mergeRequest.IID, &gitlab.ListOptions{PerPage: 20, Page: query.page})
+		if err != nil {
+			return nil, resp, err
+		}
+
+		// The endpoint can neither filter nor sort, so each page is filtered
+		// and sorted here.
+		var filtered []*gitlab.PipelineInfo
+		for _, pipeline := range pipelines {
+			if (query.status == "" || pipeline.Status == string(query.status)) &&
+				(query.source == "" || pipeline.Source == query.source) {
+				filtered = append(filtered, pipeline)
+			}
+		}
+		sortPipelines(filtered, query.order)
+		return filtered, resp, nil
+	}
+
+	showPipelineList(app, projectID, "", fmt.Sprintf("!%d", mergeRequest.IID), list, crumbs, returnTo)
+}
+
+// sortPipelines sorts pipelines in order.
+func sortPipelines(pipelines []*gitlab.PipelineInfo, order pipelineOrder) {
+	sort.SliceStable(pipelines, func(i, j int) bool {
+		a, b := pipelines[i], pipelines[j]
+		if order.orderBy == "status" && a.Status != b.Status {
+			return a.Status < b.Status
+		}
+		if order.sort == "asc" {
+			return a.ID < b.ID
+		}
+		return a.ID > b.ID
+	})
+}
+
+// showPipelineList lists the pipelines returned by list. label names them
+// in the title and messages. New pipelines are run for branch; without a
+// branch none can be run.
+func showPipelineList(app *tview.Application, projectID, branch, label string, list listPipelinesFunc, crumbs []string, returnTo tview.Primitive) {
+	statusFilter, sourceFilter, order := 0, 0, 0
+
+	// query returns the query for page with the current filter and order.
+	query := func(page int) pipelineQuery {
+		return pipelineQuery{
+			page:   page,
+			status: pipelineStatusFilters[statusFilter],
+			source: pipelineSourceFilters[sourceFilter],
+			order:  pipelineOrders[order],
+		}
+	}
+
+	// textFilter narrows the loaded pipelines down to those matching it.
+	textFilter := ""
+
+	pipelineList := tview.NewList()
+	pipelineList.SetBorder(true)
+	flex := tview.NewFlex()
+
+	filterInput := tview.NewInputField().
+		SetLabel("Filter pipelines: ")
+
+	// emptyMessage takes the place of the list while it's empty. The list
+	// keeps the focus, so its keys still work.
+	emptyMessage := buildEmptyMessage("No pipelines for " + label)
+	emptyMessage.SetBorder(true)
+
+	// noAccessMessage takes its place when GitLab denies access to the
+	// pipelines, so the rest of the project can still be browsed.
+	noAccessMessage := buildEmptyMessage("No access to the pipelines of " + label)
+	noAccessMessage.SetBorder(true)
+	denied := false
+
+	// loadedPipelines are the pipelines of the pages loaded so far and
+	// shownPipelines those of them in the list, in list order.
+	var loadedPipelines, shownPipelines []*gitlab.PipelineInfo
+
+	// details caches the job counts and triggering users of the pipelines
+	// by ID. Like the commits below they're fetched in the background and
+	// filled in as they arrive.
+	details := make(map[int]pipelineDetails)
+
+	// The background fetches stop with fetchCtx once the list is left.
+	fetchCtx, stopFetching := context.WithCancel(context.Background())
+
+	// changed holds the IDs of the pipelines whose status changed with the
+	// last auto-refresh. They're flagged in the list.
+	changed := make(map[int]bool)
+
+	// marked are the pipelines marked to be compared, at most two.
+	var marked []*gitlab.PipelineInfo
+
+	isMarked := func(pipeline *gitlab.PipelineInfo) bool {
+		for _, m := range marked {
+			if m.ID == pipeline.ID {
+				return true
+			}
+		}
+		return false
+	}
+
+	itemText := func(pipeline *gitlab.PipelineInfo) string {
+		detail, known := details[pipeline.ID]
+		text := pipelineInfo(pipeline, detail, known)
+		if changed[pipeline.ID] {
+			text = colorTag(theme.Accent) + "» [-]" + text
+		}
+		if isMarked(pipeline) {
+			text = colorTag(theme.Accent) + "✔ [-]" + text
+		}
+		return text
+	}
+
+	showDetails := func(pipelineID int) {
+		for i, pipeline := range shownPipelines {
+			if pipeline.ID == pipelineID {
+				_, secondaryText := pipelineList.GetItemText(i)
+				pipelineList.SetItemText(i, itemText(pipeline), secondaryText)
+			}
+		}
+	}
+
+	loadDetails := func(pipelines []*gitlab.PipelineInfo) {
+		var missing []int
+		for _, pipeline := range pipelines {
+			if _, ok := details[pipeline.ID]; !ok {
+				missing = append(missing, pipeline.ID)
+			}
+		}
+
+		go fetchPipelineDetails(fetchCtx, projectID, missing, func(pipelineID int, detail pipelineDetails) {
+			app.QueueUpdateDraw(func() {
+				if !isOpen(flex) {
+					stopFetching()
+					return
+				}
+				details[pipelineID] = detail
+				showDetails(pipelineID)
+			})
+		})
+	}
+
+	// commits caches the commits of the pipelines by SHA. They're fetched in
+	// the background once a page is shown and fill in the secondary text of
+	// the pipelines as they arrive.
+	commits := make(map[string]*gitlab.Commit)
+
+	showCommit := func(commit *gitlab.Commit) {
+		for i, pipeline := range shownPipelines {
+			if pipeline.SHA == commit.ID {
+				mainText, _ := pipelineList.GetItemText(i)
+				pipelineList.SetItemText(i, mainText, commitInfo(commit))
+			}
+		}
+	}
+
+	loadCommits := func(pipelines []*gitlab.PipelineInfo) {
+		var missing []string
+		for _, pipeline := range pipelines {
+			if _, ok := commits[pipeline.SHA]; !ok {
+				missing = append(missing, pipeline.SHA)
+			}
+		}
+
+		go func() {
+			fetched := make(map[string]bool)
+			for _, sha := range missing {
+				if fetched[sha] || fetchCtx.Err() != nil {
+					continue
+				}
+				fetched[sha] = true
+
+				// The commit is only extra information, so errors are
+				// only logged.
+				commit, _, err := gitlabClient.GetCommit(fetchCtx, projectID, sha)
+				if err != nil {
+					if fetchCtx.Err() == nil {
+						logError("Error fetching commit %s: %v", sha, err)
+					}
+					continue
+				}
+				app.QueueUpdateDraw(func() {
+					if !isOpen(flex) {
+						stopFetching()
+						return
+					}
+					commits[commit.ID] = commit
+					showCommit(commit)
+				})
+			}
+		}()
+	}
+
+	// The "Load more" item is always the last one in the list while there
+	// are further pages. Loading page 1 starts over with an empty list.
+	nextPage := 0
+	var loadMore func()
+
+	// firstPageSize is how many of the loaded pipelines came with the first
+	// page, the one auto-refresh fetches again.
+	firstPageSize := 0
+
+	// autoRefreshing is how often the list refreshes itself, 0 while it
+	// doesn't. stopAutoRefresh stops it.
+	var autoRefreshing time.Duration
+	var stopAutoRefresh context.CancelFunc
+
+	// render fills the list with the loaded pipelines matching the text
+	// filter, keeping the selected row.
+	render := func() {
+		index := pipelineList.GetCurrentItem()
+		pipelineList.Clear()
+		shownPipelines = nil
+
+		for _, pipeline := range loadedPipelines {
+			if !matchesPipeline(pipeline, textFilter) {
+				continue
+			}
+			pipeline := pipeline
+			shownPipelines = append(shownPipelines, pipeline)
+
+			secondaryText := ""
+			if commit, ok := commits[pipeline.SHA]; ok {
+				secondaryText = commitInfo(commit)
+			}
+			pipelineList.AddItem(itemText(pipeline), secondaryText, 0, func() {
+				fetchAndShowStages(app, projectID, fmt.Sprintf("%d", pipeline.ID), pipeline.Ref, crumbs, flex)
+			})
+		}
+
+		if nextPage != 0 {
+			pipelineList.AddItem("Load more...", "", 0, loadMore)
+		}
+
+		switch {
+		case denied:
+			flex.ResizeItem(pipelineList, 0, 0).ResizeItem(emptyMessage, 0, 0).ResizeItem(noAccessMessage, 0, 1)
+		case pipelineList.GetItemCount() == 0:
+			flex.ResizeItem(pipelineList, 0, 0).ResizeItem(emptyMessage, 0, 1).ResizeItem(noAccessMessage, 0, 0)
+		default:
+			flex.ResizeItem(pipelineList, 0, 1).ResizeItem(emptyMessage, 0, 0).ResizeItem(noAccessMessage, 0, 0)
+		}
+		pipelineList.SetCurrentItem(index)
+
+		title := pipelineListTitle(label, len(shownPipelines), nextPage != 0, query(1), textFilter)
+		if autoRefreshing > 0 {
+			title += fmt.Sprintf("- refreshing every %s ", autoRefreshing)
+		}
+		pipelineList.SetTitle(title)
+		emptyMessage.SetTitle(pipelineList.GetTitle())
+		noAccessMessage.SetTitle(pipelineList.GetTitle())
+	}
+
+	loadPage := func(page int, returnTo tview.Primitive, done func()) {
+		var pipelines []*gitlab.PipelineInfo
+		var resp *gitlab.Response
+		pageQuery := query(page)
+		loadAsync(app, "Loading pipelines...", returnTo, func(ctx context.Context) (err error) {
+			pipelines, resp, err = list(ctx, pageQuery)
+			return err
+		}, func(err error) {
+			// Only the first page can be denied; later ones are fetched
+			// with the access the first one had.
+			denied = page == 1 && isAccessDenied(err)
+			if denied {
+				pipelines, resp, err = nil, &gitlab.Response{}, nil
+			}
+			if err != nil {
+				showError(app, fmt.Sprintf("Error fetching pipelines for project %s on %s: %v", projectID, label, err), returnTo)
+				return
+			}
+
+			if page == 1 {
+				loadedPipelines = nil
+				firstPageSize = len(pipelines)
+				changed = make(map[int]bool)
+				pipelineList.SetCurrentItem(0)
+			}
+			loadedPipelines = append(loadedPipelines, pipelines...)
+			nextPage = resp.NextPage
+			render()
+			loadCommits(pipelines)
+			loadDetails(pipelines)
+
+			pushView(app, flex)
+			app.SetFocus(pipelineList)
+			if done != nil {
+				done()
+			}
+		})
+	}
+
+	loadMore = func() {
+		loadPage(nextPage, flex, nil)
+	}
+
+	// refresh reloads the first page past the cache, keeping the selected
+	// row.
+	refresh := func() {
+		clearCache()
+		details = make(map[int]pipelineDetails)
+		index := pipelineList.GetCurrentItem()
+		loadPage(1, flex, func() {
+			pipelineList.SetCurrentItem(index)
+		})
+	}
+
+	// refreshInPlace fetches the first page again in the background and
+	// updates the list without a loading modal, flagging the pipelines whose
+	// status changed. Pages loaded after the first are kept as they are.
+	refreshing := false
+	refreshInPlace := func(ctx context.Context) {
+		if refreshing {
+			return
+		}
+		refreshing = true
+		clearCache()
+
+		pageQuery := query(1)
+		go func() {
+			pipelines, _, err := list(ctx, pageQuery)
+			app.QueueUpdateDraw(func() {
+				refreshing = false
+				// Errors are left to the next refresh; the filter or order
+				// may have changed in the meantime.
+				if err != nil || ctx.Err() != nil || pageQuery != query(1) {
+					return
+				}
+
+				previous := make(map[int]string)
+				for _, pipeline := range loadedPipelines {
+					previous[pipeline.ID] = pipeline.Status
+				}
+
+				changed = make(map[int]bool)
+				fresh := make(map[int]bool)
+				for _, pipeline := range pipelines {
+					fresh[pipeline.ID] = true
+					if status, ok := previous[pipeline.ID]; ok && status != pipeline.Status {
+						changed[pipeline.ID] = true
+						delete(details, pipeline.ID)
+					}
+				}
+
+				merged := pipelines
+				if firstPageSize < len(loadedPipelines) {
+					for _, pipeline := range loadedPipelines[firstPageSize:] {
+						if !fresh[pipeline.ID] {
+							merged = append(merged, pipeline)
+						}
+					}
+				}
+				loadedPipelines = merged
+				firstPageSize = len(pipelines)
+
+				render()
+				loadCommits(pipelines)
+				loadDetails(pipelines)
+			})
+		}()
+	}
+
+	// startAutoRefresh refreshes the list in place every interval while
+	// it's the current view. Views opened from it pause the refreshes; once
+	// the list is left, however that happened, they stop.
+	startAutoRefresh := func(interval time.Duration) {
+		ctx, cancel := context.WithCancel(context.Background())
+		autoRefreshing, stopAutoRefresh = interval, cancel
+
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+
+				app.QueueUpdateDraw(func() {
+					if !isOpen(flex) {
+						cancel()
+						return
+					}
+					if ctx.Err() == nil && views[len(views)-1] == flex {
+						refreshInPlace(ctx)
+					}
+				})
+			}
+		}()
+	}
+
+	stopRefreshing := func() {
+		if stopAutoRefresh != nil {
+			stopAutoRefresh()
+			stopAutoRefresh = nil
+		}
+		autoRefreshing = 0
+	}
+
+	// back leaves the list, for good: auto-refresh and the background
+	// fetches stop with it.
+	back := func() {
+		stopRefreshing()
+		stopFetching()
+		popView(app)
+	}
+
+	showActions := func(pipeline *gitlab.PipelineInfo) {
+		actionModal := tview.NewModal().
+			SetText(fmt.Sprintf("Select Action for Pipeline %d", pipeline.ID)).
+			AddButtons([]string{"Retry failed jobs", "Cancel pipeline", "Watch pipeline", "Variables", "Back"})
+
+		runAction := func(msg, done string, action func(ctx context.Context, projectID string, pipelineID int) error) {
+			loadAsync(app, msg, flex, func(ctx context.Context) error {
+				return action(ctx, projectID, pipeline.ID)
+			}, func(err error) {
+				if err != nil {
+					showError(app, err.Error(), flex)
+					return
+				}
+				logEvent("%s pipeline %d on %s", done, pipeline.ID, pipeline.Ref)
+				refresh()
+			})
+		}
+
+		actionModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			switch buttonLabel {
+			case "Retry failed jobs":
+				// Retrying a pipeline only runs its failed and canceled jobs
+				// again; jobs that passed keep their results.
+				failed := "the failed and canceled jobs"
+				if detail, ok := details[pipeline.ID]; ok {
+					if detail.jobs.failed == 0 && pipeline.Status == "success" {
+						showMessage(app, fmt.Sprintf("Pipeline %d has no failed jobs to retry.", pipeline.ID), flex)
+						return
+					}
+					switch {
+					case detail.jobs.failed == 1:
+						failed = "the failed job, and any canceled ones,"
+					case detail.jobs.failed > 1:
+						failed = fmt.Sprintf("the %d failed jobs, and any canceled ones,", detail.jobs.failed)
+					}
+				}
+				confirm(app, fmt.Sprintf("Retry %s of pipeline %d on %s? Jobs that passed aren't run again.", failed, pipeline.ID, tview.Escape(pipeline.Ref)), flex, func() {
+					runAction("Retrying failed jobs...", "Retried the failed jobs of", retryPipeline)
+				})
+			case "Cancel pipeline":
+				confirm(app, fmt.Sprintf("Cancel pipeline %d on %s?", pipeline.ID, tview.Escape(pipeline.Ref)), flex, func() {
+					runAction("Canceling pipeline...", "Canceled", cancelPipeline)
+				})
+			case "Watch pipeline":
+				watchPipeline(app, projectID, pipeline.ID, flex)
+			case "Variables":
+				fetchAndShowVariables(app, projectID, pipeline.ID, withPipelineCrumbs(crumbs, fmt.Sprintf("%d", pipeline.ID), pipeline.Ref), flex)
+			default:
+				app.SetRoot(flex, true)
+			}
+		})
+
+		app.SetRoot(actionModal, false).SetFocus(actionModal)
+	}
+
+	pipelineList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			back()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'r':
+			refresh()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'R':
+			if autoRefreshing > 0 {
+				stopRefreshing()
+			} else if autoRefresh > 0 {
+				startAutoRefresh(autoRefresh)
+			} else {
+				startAutoRefresh(defaultAutoRefresh)
+			}
+			render()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'a':
+			// The "Load more" row has no pipeline.
+			if index := pipelineList.GetCurrentItem(); index < len(shownPipelines) {
+				showActions(shownPipelines[index])
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == ' ':
+			index := pipelineList.GetCurrentItem()
+			if index >= len(shownPipelines) {
+				return nil
+			}
+
+			// Marking a third pipeline drops the first one marked.
+			pipeline := shownPipelines[index]
+			if isMarked(pipeline) {
+				for i, m := range marked {
+					if m.ID == pipeline.ID {
+						marked = append(marked[:i], marked[i+1:]...)
+						break
+					}
+				}
+			} else {
+				if len(marked) == 2 {
+					marked = marked[1:]
+				}
+				marked = append(marked, pipeline)
+			}
+			render()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'c':
+			if len(marked) != 2 {
+				showMessage(app, "Mark two pipelines with Space to compare them.", flex)
+				return nil
+			}
+
+			before, after := marked[0], marked[1]
+			if before.ID > after.ID {
+				before, after = after, before
+			}
+			fetchAndShowComparison(app, projectID, before, after, withCrumb(crumbs, label), flex)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'y':
+			if index := pipelineList.GetCurrentItem(); index < len(shownPipelines) {
+				copyToClipboard(app, shownPipelines[index].WebURL, "the pipeline URL", flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'Y':
+			if index := pipelineList.GetCurrentItem(); index < len(shownPipelines) {
+				pipeline := shownPipelines[index]
+				glab, curl := pipelineCommands(projectID, pipeline.ID, pipeline.WebURL)
+				copyCommand(app, fmt.Sprintf("pipeline #%d", pipeline.ID), glab, curl, flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'b':
+			if index := pipelineList.GetCurrentItem(); index < len(shownPipelines) {
+				openInBrowser(app, shownPipelines[index].WebURL, flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, pipelineKeys, flex)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'n':
+			if branch == "" {
+				showMessage(app, "Select a branch to run a pipeline for.", flex)
+				return nil
+			}
+			loadAsync(app, "Creating pipeline...", flex, func(ctx context.Context) error {
+				return createPipeline(ctx, projectID, branch)
+			}, func(err error) {
+				if err != nil {
+					showError(app, err.Error(), flex)
+					return
+				}
+				logEvent("Created a pipeline for %s of project %s", branch, projectID)
+				clearCache()
+				loadPage(1, flex, nil)
+			})
+			return nil
+		case event.Key() == tcell.KeyRune && (event.Rune() == 's' || event.Rune() == 'S' || event.Rune() == 'o'):
+			switch event.Rune() {
+			case 's':
+				statusFilter = (statusFilter + 1) % len(pipelineStatusFilters)
+			case 'S':
+				sourceFilter = (sourceFilter + 1) % len(pipelineSourceFilters)
+			default:
+				order = (order + 1) % len(pipelineOrders)
+			}
+			loadPage(1, flex, nil)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '/':
+			flex.ResizeItem(filterInput, 1, 0)
+			app.SetFocus(filterInput)
+			return nil
+		}
+		return event
+	})
+
+	flex.SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, withCrumb(crumbs, label)), 1, 0, false).
+		AddItem(pipelineList, 0, 1, true).
+		AddItem(emptyMessage, 0, 0, false).
+		AddItem(noAccessMessage, 0, 0, false).
+		AddItem(filterInput, 0, 0, false).
+		AddItem(buildFooter(app, back), 1, 0, false)
+
+	// The filter input only takes up space while it's open. The filter
+	// keeps applying after it's closed.
+	filterInput.SetChangedFunc(func(text string) {
+		textFilter = text
+		pipelineList.SetCurrentItem(0)
+		render()
+	})
+
+	filterInput.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEsc {
+			filterInput.SetText("")
+		}
+		flex.ResizeItem(filterInput, 0, 0)
+		app.SetFocus(pipelineList)
+	})
+
+	loadPage(1, returnTo, func() {
+		if autoRefresh > 0 {
+			startAutoRefresh(autoRefresh)
+			render()
+		}
+	})
+}
+
+// pipelineInfo returns the text of a pipeline in the pipeline list. The job
+// counts, the stage bar and the triggering user are left out until they're
+// known.
+func pipelineInfo(pipeline *gitlab.PipelineInfo, detail pipelineDetails, known bool) string {
+	jobs, user, duration, stages := "...", "...", "...", ""
+	if known {
+		stages = " " + stageBar(detail.stages)
+		count := detail.jobs
+		jobs = fmt.Sprintf("%d", count.total)
+		switch failed := count.failed - count.allowed; {
+		case failed > 0 && count.allowed > 0:
+			jobs = fmt.Sprintf("%s%d failed[-], %s%d allowed to fail[-] of %d",
+				statusColor("failed"), failed, statusColor("warning"), count.allowed, count.total)
+		case failed > 0:
+			jobs = fmt.Sprintf("%s%d failed[-] of %d", statusColor("failed"), failed, count.total)
+		case count.allowed > 0:
+			jobs = fmt.Sprintf("%s%d allowed to fail[-] of %d", statusColor("warning"), count.allowed, count.total)
+		}
+
+		// Scheduled and triggered pipelines have no user; their source
+		// tells what started them.
+		user = sourceLabel(pipeline.Source)
+		if detail.user != "" {
+			user = tview.Escape(detail.user)
+		}
+
+		duration = "-"
+		if detail.duration > 0 {
+			duration = humanizeDuration(float64(detail.duration))
+		}
+	}
+
+	sha := pipeline.SHA
+	if len(sha) > 8 {
+		sha = sha[:8]
+	}
+
+	values := map[string]string{
+		"id":       fmt.Sprintf("%d", pipeline.ID),
+		"status":   statusColor(pipeline.Status) + pipeline.Status + "[-]" + stages,
+		"ref":      tview.Escape(pipeline.Ref),
+		"source":   sourceLabel(pipeline.Source),
+		"user":     user,
+		"jobs":     jobs,
+		"updated":  pipeline.UpdatedAt.Format("2006-01-02 15:04:05"),
+		"duration": duration,
+		"sha":      sha,
+	}
+
+	var text strings.Builder
+	for _, column := range pipelineColumns {
+		fmt.Fprintf(&text, "%s: %s \n", pipelineColumnLabels[column], values[column])
+	}
+	return text.String()
+}
+
+// jobCount is the number of jobs of a pipeline, how many of them failed and
+// how many of the failed ones were allowed to fail.
+type jobCount struct {
+	total   int
+	failed  int
+	allowed int
+}
+
+// pipelineDetails is what the pipeline list shows of a pipeline beyond what
+// listing pipelines returns: its job counts, the statuses of its stages, the
+// user who triggered it, if any, and how many seconds it ran.
+type pipelineDetails struct {
+	jobs     jobCount
+	stages   []string
+	user     string
+	duration int
+}
+
+// fetchPipelineDetails fetches the details of the pipelines with a pool of
+// maxConcurrency workers, calling found with each as it arrives. Pipelines
+// whose details can't be fetched are skipped, they're only extra information;
+// the errors are logged. Once ctx is done the rest are skipped too.
+func fetchPipelineDetails(ctx context.Context, projectID string, pipelineIDs []int, found func(pipelineID int, detail pipelineDetails)) {
+	ids := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < maxConcurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				jobs, err := listPipelineJobs(ctx, gitlabClient, projectID, fmt.Sprintf("%d", id))
+				if err != nil {
+					if ctx.Err() == nil {
+						logError("%v", err)
+					}
+					continue
+				}
+				pipeline, _, err := gitlabClient.GetPipeline(ctx, projectID, id)
+				if err != nil {
+					if ctx.Err() == nil {
+						logError("Error fetching pipeline %d: %v", id, err)
+					}
+					continue
+				}
+
+				detail := pipelineDetails{jobs: countJobs(jobs), stages: stageStatuses(jobs), duration: pipeline.Duration}
+				if pipeline.User != nil {
+					detail.user = pipeline.User.Username
+				}
+				found(id, detail)
+			}
+		}()
+	}
+
+feed:
+	for _, id := range pipelineIDs {
+		select {
+		case ids <- id:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(ids)
+	wg.Wait()
+}
+
+// countJobs counts jobs, how many of them failed and how many of those were
+// allowed to.
+func countJobs(jobs []*gitlab.Job) jobCount {
+	count := jobCount{total: len(jobs)}
+	for _, job := range jobs {
+		if job.Status == "failed" {
+			count.failed++
+			if job.AllowFailure {
+				count.allowed++
+			}
+		}
+	}
+	return count
+}
+
+// commitInfo returns the short SHA, title and author of a commit.
+func commitInfo(commit *gitlab.Commit) string {
+	return tview.Escape(fmt.Sprintf("%s %s - %s", commit.ShortID, commit.Title, commit.AuthorName))
+}
+
+func createPipeline(ctx context.Context, projectID, branch string) error {
+	_, _, err := gitlabClient.CreatePipeline(ctx, projectID, &gitlab.CreatePipelineOptions{Ref: &branch})
+	if err != nil {
+		return fmt.Errorf("Error creating pipeline for branch %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+func retryPipeline(ctx context.Context, projectID string, pipelineID int) error {
+	_, _, err := gitlabClient.RetryPipelineBuild(ctx, projectID, pipelineID)
+	if err != nil {
+		return fmt.Errorf("Error retrying pipeline: %w", err)
+	}
+
+	return nil
+}
+
+func cancelPipeline(ctx context.Context, projectID string, pipelineID int) error {
+	_, _, err := gitlabClient.CancelPipelineBuild(ctx, projectID, pipelineID)
+	if err != nil {
+		return fmt.Errorf("Error canceling pipeline: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,256 @@
+// stages.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// stageKeys are the shortcuts of the stage list.
+var stageKeys = []keyHelp{
+	{"Enter", "Show the jobs of the stage or the downstream pipeline"},
+	{"j", "Show all jobs"},
+	{"r", "Refresh"},
+	{"Esc", "Back"},
+}
+
+// fetchAndShowStages lists the stages of a pipeline. crumbs is the
+// breadcrumb of the project.
+func fetchAndShowStages(app *tview.Application, projectID, pipelineID, pipelineName string, crumbs []string, returnTo tview.Primitive) {
+	var (
+		pipelineJobs []*gitlab.Job
+		bridges      []*gitlab.Bridge
+	)
+	loadAsync(app, "Loading jobs...", returnTo, func(ctx context.Context) (err error) {
+		pipelineJobs, bridges, err = listStageJobs(ctx, gitlabClient, projectID, pipelineID)
+		return err
+	}, func(err error) {
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
+
+		pushView(app, buildStageListView(app, pipelineJobs, bridges, projectID, pipelineID, pipelineName, crumbs))
+	})
+}
+
+// listStageJobs returns the jobs and the bridge jobs of a pipeline. Without
+// access to the bridge jobs only the jobs are returned.
+func listStageJobs(ctx context.Context, gl GitLab, projectID, pipelineID string) ([]*gitlab.Job, []*gitlab.Bridge, error) {
+	jobs, err := listPipelineJobs(ctx, gl, projectID, pipelineID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bridges, err := listPipelineBridges(ctx, gl, projectID, pipelineID)
+	if err != nil && !isAccessDenied(err) {
+		return nil, nil, err
+	}
+	return jobs, bridges, nil
+}
+
+// buildStageListView returns the list of the stages of a pipeline with the
+// status of each, followed by the bridge jobs that trigger downstream
+// pipelines. Selecting a stage lists its jobs; j lists all of them. Selecting
+// a bridge job lists the stages of its downstream pipeline.
+func buildStageListView(app *tview.Application, pipelineJobs []*gitlab.Job, bridges []*gitlab.Bridge, projectID, pipelineID, pipelineName string, crumbs []string) *tview.Flex {
+	stageList := tview.NewList().ShowSecondaryText(false)
+	stageList.SetBorder(true)
+	flex := tview.NewFlex()
+
+	// emptyMessage takes the place of the list while it's empty. The list
+	// keeps the focus, so its keys still work.
+	emptyMessage := buildEmptyMessage("No jobs in this pipeline")
+	emptyMessage.SetBorder(true)
+
+	header, reloadHeader := buildPipelineHeader(app, projectID, pipelineID)
+
+	var stages []*jobStage
+
+	setStages := func(jobs []*gitlab.Job, bridges []*gitlab.Bridge) {
+		pipelineJobs = jobs
+		stages = groupJobsByStage(jobs)
+		stageList.Clear()
+
+		title := fmt.Sprintf(" Stages of #%s (%d) ", pipelineID, len(stages))
+		if len(bridges) > 0 {
+			title = fmt.Sprintf(" Stages of #%s (%d) - %d downstream ", pipelineID, len(stages), len(bridges))
+		}
+		stageList.SetTitle(title)
+		emptyMessage.SetTitle(title)
+
+		for _, stage := range stages {
+			stage := stage
+			stageList.AddItem(stageInfo(stage), "", 0, func() {
+				pushView(app, rebuildJobListView(app, stage.jobs, projectID, pipelineID, pipelineName, stage.name, crumbs))
+			})
+		}
+
+		for _, bridge := range bridges {
+			bridge := bridge
+			stageList.AddItem(bridgeInfo(bridge, projectID), "", 0, func() {
+				showDownstreamPipeline(app, bridge, projectID, crumbs, flex)
+			})
+		}
+
+		if stageList.GetItemCount() == 0 {
+			flex.ResizeItem(stageList, 0, 0).ResizeItem(emptyMessage, 0, 1)
+		} else {
+			flex.ResizeItem(stageList, 0, 1).ResizeItem(emptyMessage, 0, 0)
+		}
+	}
+
+	refresh := func() {
+		var (
+			jobs    []*gitlab.Job
+			bridges []*gitlab.Bridge
+		)
+		loadAsync(app, "Loading jobs...", flex, func(ctx context.Context) (err error) {
+			jobs, bridges, err = listStageJobs(ctx, gitlabClient, projectID, pipelineID)
+			return err
+		}, func(err error) {
+			if err != nil {
+				showError(app, err.Error(), flex)
+				return
+			}
+
+			reloadHeader()
+			index := stageList.GetCurrentItem()
+			setStages(jobs, bridges)
+			stageList.SetCurrentItem(index)
+			app.SetRoot(flex, true)
+		})
+	}
+
+	stageList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			popView(app)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, stageKeys, flex)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'j':
+			pushView(app, rebuildJobListView(app, pipelineJobs, projectID, pipelineID, pipelineName, "", crumbs))
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'r':
+			refresh()
+			return nil
+		}
+		return event
+	})
+
+	flex.SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, withCrumb(withPipelineCrumbs(crumbs, pipelineID, pipelineName), "stages")), 1, 0, false).
+		AddItem(header, 1, 0, false).
+		AddItem(stageList, 0, 1, true).
+		AddItem(emptyMessage, 0, 0, false).
+		AddItem(buildFooter(app, func() {
+			popView(app)
+		}), 1, 0, false)
+
+	setStages(pipelineJobs, bridges)
+	return flex
+}
+
+// stageInfo returns the text of a stage in the stage list.
+func stageInfo(stage *jobStage) string {
+	status := stageStatus(stage.jobs)
+	statusText := status
+	if status == "warning" {
+		statusText = "passed with warnings"
+	}
+
+	count := countJobs(stage.jobs)
+	var failures []string
+	if failed := count.failed - count.allowed; failed > 0 {
+		failures = append(failures, fmt.Sprintf("%s%d failed[-]", statusColor("failed"), failed))
+	}
+	if count.allowed > 0 {
+		failures = append(failures, fmt.Sprintf("%s%d allowed to fail[-]", statusColor("warning"), count.allowed))
+	}
+	jobs := fmt.Sprintf("%d", count.total)
+	if len(failures) > 0 {
+		jobs += " (" + strings.Join(failures, ", ") + ")"
+	}
+
+	return fmt.Sprintf("Stage: %s \nStatus: %s%s[-] \nJobs: %s \n", tview.Escape(stage.name), statusColor(status), statusText, jobs)
+}
+
+// stageStatusPrecedence lists job statuses from the one that decides the
+// status of a stage first to the one that decides it last.
+var stageStatusPrecedence = []string{"running", "pending", "failed", "canceled", "manual", "scheduled", "created", "success", "skipped"}
+
+// stageStatuses returns the status of every stage of a pipeline, in the order
+// of groupJobsByStage.
+func stageStatuses(jobs []*gitlab.Job) []string {
+	var statuses []string
+	for _, stage := range groupJobsByStage(jobs) {
+		statuses = append(statuses, stageStatus(stage.jobs))
+	}
+	return statuses
+}
+
+// stageBar returns a compact progress bar of a pipeline with a symbol per
+// stage, e.g. [✓✓✗··] for one that failed in its third of five stages. It's
+// empty for pipelines without stages.
+func stageBar(statuses []string) string {
+	if len(statuses) == 0 {
+		return ""
+	}
+
+	var bar strings.Builder
+	bar.WriteString("[")
+	for _, status := range statuses {
+		symbol := "·"
+		switch status {
+		case "success":
+			symbol = "✓"
+		case "warning":
+			symbol = "!"
+		case "failed", "canceled":
+			symbol = "✗"
+		case "running":
+			symbol = "▸"
+		}
+		bar.WriteString(statusColor(status) + symbol + "[-]")
+	}
+	bar.WriteString("] ")
+	return bar.String()
+}
+
+// stageStatus returns the status of a stage computed from its jobs, much like
+// GitLab does: a stage is running while any of its jobs runs, failed once one
+// failed, and so on. Jobs allowed to fail count as successful, but a stage
+// that would have failed without that is "warning", GitLab's "passed with
+// warnings".
+func stageStatus(jobs []*gitlab.Job) string {
+	statuses := make(map[string]bool)
+	warnings := false
+	for _, job := range jobs {
+		status := job.Status
+		switch {
+		case status == "failed" && job.AllowFailure:
+			status = "success"
+			warnings = true
+		case status == "preparing" || status == "waiting_for_resource":
+			status = "pending"
+		}
+		statuses[status] = true
+	}
+
+	for _, status := range stageStatusPrecedence {
+		if statuses[status] {
+			if status == "success" && warnings {
+				return "warning"
+			}
+			return status
+		}
+	}
+	return "created"
+}
@@ -0,0 +1,43 @@
+// stages_test.go
+package main
+
+import (
+	"testing"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// job returns a job with status, allowed to fail with allowFailure.
+func job(status string, allowFailure bool) *gitlab.Job {
+	return &gitlab.Job{Status: status, AllowFailure: allowFailure}
+}
+
+func TestStageStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		jobs []*gitlab.Job
+		want string
+	}{
+		{name: "no jobs", want: "created"},
+		{name: "all passed", jobs: []*gitlab.Job{job("success", false), job("success", false)}, want: "success"},
+		{name: "one failed", jobs: []*gitlab.Job{job("success", false), job("failed", false)}, want: "failed"},
+		{name: "running beats failed", jobs: []*gitlab.Job{job("failed", false), job("running", false)}, want: "running"},
+		{name: "preparing counts as pending", jobs: []*gitlab.Job{job("success", false), job("preparing", false)}, want: "pending"},
+		{name: "waiting for resource counts as pending", jobs: []*gitlab.Job{job("waiting_for_resource", false)}, want: "pending"},
+		{name: "allowed failure", jobs: []*gitlab.Job{job("success", false), job("failed", true)}, want: "warning"},
+		{name: "allowed failure and real failure", jobs: []*gitlab.Job{job("failed", true), job("failed", false)}, want: "failed"},
+		{name: "skipped after success", jobs: []*gitlab.Job{job("skipped", false), job("success", false)}, want: "success"},
+		{name: "only skipped", jobs: []*gitlab.Job{job("skipped", false)}, want: "skipped"},
+		{name: "manual", jobs: []*gitlab.Job{job("manual", false), job("success", false)}, want: "manual"},
+		{name: "canceled", jobs: []*gitlab.Job{job("canceled", false), job("success", false)}, want: "canceled"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stageStatus(tt.jobs); got != tt.want {
+				t.Errorf("stageStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// state.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rivo/tview"
+)
+
+// State is where the user last was, saved so the next start can resume
+// there, along with the projects they pinned.
+type State struct {
+	Profile   string   `json:"profile"`
+	Groups    []string `json:"groups"`
+	ProjectID string   `json:"project_id"`
+	Project   string   `json:"project"`
+	Branch    string   `json:"branch"`
+
+	Favorites []Favorite `json:"favorites,omitempty"`
+}
+
+// Favorite is a project pinned to the top of the group tree of the instance
+// of Profile. Name is its path of group and project names.
+type Favorite struct {
+	Profile   string `json:"profile"`
+	ProjectID string `json:"project_id"`
+	Name      string `json:"name"`
+}
+
+// canResume reports whether the state has a location to resume at.
+func (s *State) canResume() bool {
+	return s != nil && s.ProjectID != ""
+}
+
+// favorites returns the pinned projects of the active profile.
+func (s *State) favorites() []Favorite {
+	if s == nil {
+		return nil
+	}
+
+	var favorites []Favorite
+	for _, favorite := range s.Favorites {
+		if favorite.Profile == activeProfile {
+			favorites = append(favorites, favorite)
+		}
+	}
+	return favorites
+}
+
+func defaultStatePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gitlab-pipe-viewer", "state.json")
+}
+
+// loadState reads the saved state. It returns nil if nothing was saved yet.
+func loadState() (*State, error) {
+	path := defaultStatePath()
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading state %s: %w", path, err)
+	}
+
+	state := &State{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parsing state %s: %w", path, err)
+	}
+
+	return state, nil
+}
+
+func saveState(state *State) error {
+	path := defaultStatePath()
+	if path == "" {
+		return errors.New("no home directory to save state in")
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("saving state %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("saving state %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// saveLocation saves where the user is, keeping the favorites.
+func saveLocation(groups []string, projectID, project, branch string) error {
+	// A broken state file is overwritten rather than kept forever.
+	state, _ := loadState()
+	if state == nil {
+		state = &State{}
+	}
+
+	state.Profile = activeProfile
+	state.Groups = groups
+	state.ProjectID = projectID
+	state.Project = project
+	state.Branch = branch
+	return saveState(state)
+}
+
+// toggleFavorite pins the project with projectID for the active profile, or
+// unpins it if it's pinned already. It reports whether it's pinned now.
+func toggleFavorite(projectID, name string) (bool, error) {
+	state, err := loadState()
+	if err != nil {
+		return false, err
+	}
+	if state == nil {
+		state = &State{}
+	}
+
+	for i, favorite := range state.Favorites {
+		if favorite.Profile == activeProfile && favorite.ProjectID == projectID {
+			state.Favorites = append(state.Favorites[:i], state.Favorites[i+1:]...)
+			return false, saveState(state)
+		}
+	}
+
+	state.Favorites = append(state.Favorites, Favorite{Profile: activeProfile, ProjectID: projectID, Name: name})
+	return true, saveState(state)
+}
+
+// resume switches to the profile of state and shows the pipelines of its
+// branch.
+func resume(app *tview.Application, state *State, returnTo tview.Primitive) {
+	show := func() {
+		crumbs := withCrumb([]string{instanceCrumb()}, state.Groups...)
+		crumbs = withCrumb(crumbs, state.Project)
+		fetchAndShowPipelines(app, state.ProjectID, state.Branch, crumbs, returnTo)
+	}
+
+	if state.Profile == activeProfile {
+		show()
+		return
+	}
+
+	profile, ok := findProfile(profiles, state.Profile)
+	if !ok {
+		showError(app, "Unknown profile: "+state.Profile, returnTo)
+		return
+	}
+	connect(app, profile, returnTo, show)
+}
@@ -0,0 +1,101 @@
+// theme.go
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme maps the roles of UI elements to colors. Colors are names such as
+// "orangered" or hex values like "#ff8700".
+type Theme struct {
+	Header   string `yaml:"header"`
+	Instance string `yaml:"instance"`
+	Group    string `yaml:"group"`
+	Project  string `yaml:"project"`
+	Accent   string `yaml:"accent"`
+	Field    string `yaml:"field"`
+	// FieldBackground is the background of the branch drop-down.
+	FieldBackground string `yaml:"field_background"`
+	Success         string `yaml:"success"`
+	Failed          string `yaml:"failed"`
+	Running         string `yaml:"running"`
+	Canceled        string `yaml:"canceled"`
+	Manual          string `yaml:"manual"`
+	Warning         string `yaml:"warning"`
+}
+
+var defaultTheme = Theme{
+	Header:          "yellow",
+	Instance:        "orangered",
+	Group:           "whitesmoke",
+	Project:         "darkgrey",
+	Accent:          "orange",
+	Field:           "orangered",
+	FieldBackground: "darkgray",
+	Success:         "green",
+	Failed:          "red",
+	Running:         "yellow",
+	Canceled:        "grey",
+	Manual:          "blue",
+	Warning:         "darkorange",
+}
+
+// theme is the theme in use.
+var theme = defaultTheme
+
+// withDefaults returns t with unset colors taken from the default theme. It
+// fails on colors that aren't known.
+func (t Theme) withDefaults() (Theme, error) {
+	colors := []struct {
+		role     string
+		color    *string
+		fallback string
+	}{
+		{"header", &t.Header, defaultTheme.Header},
+		{"instance", &t.Instance, defaultTheme.Instance},
+		{"group", &t.Group, defaultTheme.Group},
+		{"project", &t.Project, defaultTheme.Project},
+		{"accent", &t.Accent, defaultTheme.Accent},
+		{"field", &t.Field, defaultTheme.Field},
+		{"field_background", &t.FieldBackground, defaultTheme.FieldBackground},
+		{"success", &t.Success, defaultTheme.Success},
+		{"failed", &t.Failed, defaultTheme.Failed},
+		{"running", &t.Running, defaultTheme.Running},
+		{"canceled", &t.Canceled, defaultTheme.Canceled},
+		{"manual", &t.Manual, defaultTheme.Manual},
+		{"warning", &t.Warning, defaultTheme.Warning},
+	}
+
+	for _, c := range colors {
+		if *c.color == "" {
+			*c.color = c.fallback
+			continue
+		}
+
+		*c.color = strings.ToLower(*c.color)
+		if *c.color != "default" && tcell.GetColor(*c.color) == tcell.ColorDefault {
+			return t, fmt.Errorf("unknown %s color %q", c.role, *c.color)
+		}
+	}
+
+	return t, nil
+}
+
+// themeColor returns the tcell color for a theme color.
+func themeColor(name string) tcell.Color {
+	return tcell.GetColor(name)
+}
+
+// colorTag returns the tview color tag for a theme color.
+func colorTag(name string) string {
+	return "[" + name + "]"
+}
+
+// boldColorTag returns the tview tag for bold text in a theme color. It's
+// reset with "[-::-]".
+func boldColorTag(name string) string {
+	return "[" + name + "::b]"
+}
@@ -0,0 +1,808 @@
+// tree.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// showTree shows the group tree right away and fills in the groups as they
+// arrive, so the first ones can be browsed while the rest is loading.
+func showTree(app *tview.Application, searchTerm string) {
+	view, load := buildTree(app, searchTerm)
+	pushView(app, view)
+	load()
+}
+
+// buildTree returns the group tree along with a func that loads the groups
+// into it.
+func buildTree(app *tview.Application, searchTerm string) (*tview.Flex, func()) {
+	root := tview.NewTreeNode("GitLab Pipelines").
+		SetColor(themeColor(theme.Header)).
+		SetSelectable(false)
+
+	tree := tview.NewTreeView().
+		SetRoot(root).
+		SetCurrentNode(root).
+		SetTopLevel(1).
+		SetGraphicsColor(themeColor(theme.Accent))
+	tree.SetBorder(true)
+
+	// memberOnly lists the projects the user is a member of by namespace
+	// instead of all groups.
+	memberOnly := membershipOnly
+
+	// loadedGroups counts the groups loaded so far while loading is set.
+	// stopLoading stops loading them.
+	loading, loadedGroups := false, 0
+	var stopLoading context.CancelFunc
+
+	// groupsNode is the node of the instance with the groups below it. The
+	// pinned projects come before it.
+	var groupsNode *tview.TreeNode
+	setChildren := func() {
+		root.ClearChildren()
+		// A broken state file only loses the favorites.
+		state, _ := loadState()
+		if favorites := state.favorites(); len(favorites) > 0 {
+			root.AddChild(newFavoritesNode(favorites))
+		}
+		root.AddChild(groupsNode)
+	}
+
+	filterInput := tview.NewInputField().
+		SetLabel("Filter projects: ")
+
+	searchInput := tview.NewInputField().
+		SetLabel("Search: ")
+
+	flex := tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, []string{instanceCrumb()}), 1, 0, false).
+		AddItem(tree, 0, 1, true).
+		AddItem(filterInput, 0, 0, false).
+		AddItem(searchInput, 0, 0, false).
+		AddItem(buildFooter(app, func() {
+			popView(app)
+		}), 1, 0, false)
+
+	// onlyActive hides the projects without activity in the last
+	// activityDays days. withArchived shows archived projects.
+	onlyActive, withArchived := false, showArchived
+
+	setTitle := func() {
+		title := " Groups "
+		if memberOnly {
+			title = " Your projects "
+		}
+		if onlyActive {
+			title += fmt.Sprintf("- projects active in the last %d days ", activityDays)
+		}
+		if withArchived {
+			title += "- with archived projects "
+		}
+		if loading {
+			title += fmt.Sprintf("- loaded %d groups... ", loadedGroups)
+		}
+		tree.SetTitle(title)
+	}
+	setTitle()
+
+	currentFilter := func() projectFilter {
+		filter := projectFilter{name: filterInput.GetText(), showArchived: withArchived}
+		if onlyActive {
+			filter.activeSince = time.Now().AddDate(0, 0, -activityDays)
+		}
+		return filter
+	}
+
+	// The filter input stays in the layout but only takes up space while
+	// it's open. The filter keeps applying after it's closed.
+	filterInput.SetChangedFunc(func(text string) {
+		filterProjects(root, currentFilter())
+	})
+
+	filterInput.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEsc {
+			filterInput.SetText("")
+		}
+		flex.ResizeItem(filterInput, 0, 0)
+		app.SetFocus(tree)
+	})
+
+	// highlighted holds the original colors of the nodes highlighted as
+	// matches of the search.
+	highlighted := make(map[*tview.TreeNode]tcell.Color)
+
+	// search highlights the nodes matching the search and selects the next
+	// one in the direction of step, or for a step of 0 the first one from
+	// the selected node on. The search is run again every time, so it covers
+	// groups expanded since.
+	search := func(step int) {
+		for node, color := range highlighted {
+			node.SetColor(color)
+			delete(highlighted, node)
+		}
+
+		matches, next := searchTree(root, tree.GetCurrentNode(), searchInput.GetText(), step)
+		for _, node := range matches {
+			highlighted[node] = node.GetColor()
+			node.SetColor(themeColor(theme.Accent))
+		}
+		if next != nil {
+			revealNode(tree, next)
+		}
+	}
+
+	// Like the filter input, the search input only takes up space while
+	// it's open. Its matches stay highlighted after it's closed.
+	searchInput.SetChangedFunc(func(text string) {
+		search(0)
+	})
+
+	searchInput.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEsc {
+			searchInput.SetText("")
+		}
+		flex.ResizeItem(searchInput, 0, 0)
+		app.SetFocus(tree)
+	})
+
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		if _, ok := node.GetReference().(*groupRef); ok {
+			expandGroup(app, node, currentFilter(), flex)
+			return
+		}
+		if _, ok := node.GetReference().(favoritesRef); ok {
+			node.SetExpanded(!node.IsExpanded())
+			return
+		}
+
+		projectName := node.GetText()
+		if strings.HasPrefix(projectName, "Project: ") {
+			showPipelines(app, node, pathCrumbs(tree.GetPath(node)), flex)
+		}
+	})
+
+	// streamGroups fills the tree with the groups page by page as they
+	// arrive, selecting the node matching current once it's there unless
+	// the user has moved on.
+	streamGroups := func(current *tview.TreeNode) {
+		ctx, cancel := context.WithCancel(context.Background())
+		stopLoading = cancel
+
+		groupsNode = newInstanceNode()
+		setChildren()
+		loading, loadedGroups = true, 0
+		setTitle()
+
+		go func() {
+			err := listGroups(ctx, gitlabClient, searchTerm, func(groups []*gitlab.Group, fetched int) {
+				app.QueueUpdateDraw(func() {
+					if ctx.Err() != nil {
+						return
+					}
+					for _, group := range groups {
+						groupsNode.AddChild(newGroupNode(group))
+					}
+					loadedGroups = fetched
+					setTitle()
+					if !isInTree(root, tree.GetCurrentNode()) {
+						selectMatchingNode(tree, current)
+					}
+				})
+			})
+
+			app.QueueUpdateDraw(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				loading = false
+				setTitle()
+				if err != nil {
+					showError(app, err.Error(), flex)
+				}
+			})
+		}()
+	}
+
+	// reload builds the tree below the instance again, keeping the selected
+	// node where it's still there.
+	reload := func() {
+		if stopLoading != nil {
+			stopLoading()
+			stopLoading = nil
+		}
+		loading = false
+
+		current := tree.GetCurrentNode()
+		if !memberOnly {
+			streamGroups(current)
+			return
+		}
+
+		var refreshed *tview.TreeNode
+		loadAsync(app, "Loading projects...", flex, func(ctx context.Context) (err error) {
+			refreshed, err = buildMemberProjects(ctx, gitlabClient, searchTerm)
+			return err
+		}, func(err error) {
+			groupsNode = refreshed
+			setChildren()
+			filterProjects(root, currentFilter())
+			selectMatchingNode(tree, current)
+
+			app.SetRoot(flex, true)
+			if err != nil {
+				showError(app, err.Error(), flex)
+			}
+		})
+	}
+
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			if stopLoading != nil {
+				stopLoading()
+			}
+			popView(app)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, treeKeys, flex)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'l':
+			node := tree.GetCurrentNode()
+			if ref, ok := node.GetReference().(*projectRef); ok {
+				showLatestPipeline(app, ref.id, pathCrumbs(tree.GetPath(node)), flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'v':
+			node := tree.GetCurrentNode()
+			if ref, ok := node.GetReference().(*projectRef); ok {
+				fetchAndShowLint(app, ref.id, pathCrumbs(tree.GetPath(node)), flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'b':
+			// GitLab redirects /projects/<id> to the project's page.
+			if ref, ok := tree.GetCurrentNode().GetReference().(*projectRef); ok {
+				openInBrowser(app, gitlabURL+"/projects/"+ref.id, flex)
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'p':
+			node := tree.GetCurrentNode()
+			ref, ok := node.GetReference().(*projectRef)
+			if !ok {
+				return nil
+			}
+			name := strings.Join(pathCrumbs(tree.GetPath(node))[1:], "/")
+			if _, err := toggleFavorite(ref.id, name); err != nil {
+				showError(app, fmt.Sprintf("Error saving favorites: %v", err), flex)
+				return nil
+			}
+
+			setChildren()
+			// Unpinning from the favorites removes the selected node.
+			if !isInTree(root, node) {
+				tree.SetCurrentNode(root.GetChildren()[0])
+			}
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'a':
+			onlyActive = !onlyActive
+			setTitle()
+			filterProjects(root, currentFilter())
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'A':
+			withArchived = !withArchived
+			setTitle()
+			filterProjects(root, currentFilter())
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'm':
+			memberOnly = !memberOnly
+			setTitle()
+			reload()
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'f':
+			flex.ResizeItem(filterInput, 1, 0)
+			app.SetFocus(filterInput)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '/':
+			flex.ResizeItem(searchInput, 1, 0)
+			app.SetFocus(searchInput)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'n':
+			search(1)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'N':
+			search(-1)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == 'r':
+			clearCache()
+			reload()
+			return nil
+		}
+		return event
+	})
+
+	return flex, reload
+}
+
+// treeKeys are the shortcuts of the group tree.
+var treeKeys = []keyHelp{
+	{"Enter", "Expand group / show pipelines"},
+	{"l", "Show the jobs of the latest pipeline"},
+	{"v", "Validate the CI/CD configuration"},
+	{"/", "Search groups and projects"},
+	{"n / N", "Next / previous match"},
+	{"f", "Filter projects"},
+	{"a", "Toggle hiding inactive projects"},
+	{"A", "Toggle showing archived projects"},
+	{"m", "Toggle listing only your projects"},
+	{"p", "Pin / unpin project to the favorites"},
+	{"b", "Open project in browser"},
+	{"r", "Refresh"},
+	{"Esc", "Back"},
+}
+
+// selectMatchingNode selects the node of tree with the same text and reference
+// as previous, which usually belongs to an earlier incarnation of the tree.
+func selectMatchingNode(tree *tview.TreeView, previous *tview.TreeNode) {
+	if previous == nil {
+		return
+	}
+
+	found := false
+	tree.GetRoot().Walk(func(node, parent *tview.TreeNode) bool {
+		if !found && node.GetText() == previous.GetText() && sameReference(node.GetReference(), previous.GetReference()) {
+			tree.SetCurrentNode(node)
+			found = true
+		}
+		return !found
+	})
+}
+
+// isInTree reports whether node is root or below it.
+func isInTree(root, node *tview.TreeNode) bool {
+	found := false
+	root.Walk(func(n, parent *tview.TreeNode) bool {
+		found = found || n == node
+		return !found
+	})
+	return found
+}
+
+// pathCrumbs returns the breadcrumb for a path of tree nodes: the instance
+// followed by the names of the groups and the project on it.
+func pathCrumbs(path []*tview.TreeNode) []string {
+	crumbs := []string{instanceCrumb()}
+	for _, node := range path {
+		if name := nodeName(node); name != "" {
+			crumbs = append(crumbs, name)
+		}
+	}
+	return crumbs
+}
+
+// nodeName returns the name of the group or project of node, or "" for other
+// nodes.
+func nodeName(node *tview.TreeNode) string {
+	switch ref := node.GetReference().(type) {
+	case *groupRef:
+		return ref.name
+	case *projectRef:
+		return strings.TrimSuffix(strings.TrimPrefix(node.GetText(), "Project: "), noAccessSuffix)
+	}
+	return ""
+}
+
+// noAccessSuffix marks the nodes of the groups and projects whose contents
+// GitLab refused to list.
+const noAccessSuffix = " (no access)"
+
+// markNoAccess marks node as one the user can see but not look into.
+func markNoAccess(node *tview.TreeNode) {
+	if !strings.HasSuffix(node.GetText(), noAccessSuffix) {
+		node.SetText(node.GetText() + noAccessSuffix).SetColor(themeColor(theme.Canceled))
+	}
+}
+
+// searchTree returns the group and project nodes below root whose name
+// contains term, in the order they appear in the tree, along with the match
+// to select: the next one after current in the direction of step, or for a
+// step of 0 current itself if it matches or else the next one. Matching
+// wraps around. Only groups loaded so far are searched.
+func searchTree(root, current *tview.TreeNode, term string, step int) ([]*tview.TreeNode, *tview.TreeNode) {
+	if term == "" {
+		return nil, nil
+	}
+
+	// at is the number of matches before current.
+	var matches []*tview.TreeNode
+	at := 0
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if node == current {
+			at = len(matches)
+		}
+		if name := nodeName(node); name != "" && matchesName(name, term) {
+			matches = append(matches, node)
+		}
+		return true
+	})
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	next := at
+	switch {
+	case step > 0 && at < len(matches) && matches[at] == current:
+		next = at + 1
+	case step < 0:
+		next = at - 1
+	}
+	return matches, matches[(next+len(matches))%len(matches)]
+}
+
+// revealNode selects node, expanding the groups it's in.
+func revealNode(tree *tview.TreeView, node *tview.TreeNode) {
+	path := tree.GetPath(node)
+	for _, parent := range path[:len(path)-1] {
+		parent.SetExpanded(true)
+	}
+	tree.SetCurrentNode(node)
+}
+
+func sameReference(a, b interface{}) bool {
+	switch a := a.(type) {
+	case *groupRef:
+		b, ok := b.(*groupRef)
+		return ok && a.id == b.id
+	case *projectRef:
+		b, ok := b.(*projectRef)
+		return ok && a.id == b.id
+	}
+	return a == b
+}
+
+// groupRef is the reference of group nodes in the tree. A group's subgroups
+// and projects are only fetched once the node is first expanded. children
+// holds all of them, including projects hidden by the project filter.
+type groupRef struct {
+	id       int
+	name     string
+	loaded   bool
+	children []*tview.TreeNode
+}
+
+// favoritesRef is the reference of the node of the pinned projects.
+type favoritesRef struct{}
+
+// newFavoritesNode returns the node listing the pinned projects. Their
+// names are their whole paths, the groups aren't in the tree above them.
+func newFavoritesNode(favorites []Favorite) *tview.TreeNode {
+	node := tview.NewTreeNode("★ Favorites").
+		SetColor(themeColor(theme.Header)).
+		SetReference(favoritesRef{})
+	for _, favorite := range favorites {
+		node.AddChild(tview.NewTreeNode("Project: " + favorite.Name).
+			SetColor(themeColor(theme.Project)).
+			SetReference(&projectRef{id: favorite.ProjectID}))
+	}
+	return node
+}
+
+// projectRef is the reference of project nodes in the tree.
+type projectRef struct {
+	id           string
+	lastActivity *time.Time
+	archived     bool
+}
+
+// membershipOnly is whether group trees start with only the projects the
+// user is a member of.
+var membershipOnly bool
+
+// showArchived is whether group trees start with archived projects shown.
+var showArchived bool
+
+// activityDays is how many days without activity hide a project while
+// inactive projects are hidden.
+var activityDays = defaultActivityDays
+
+const defaultActivityDays = 30
+
+// projectFilter selects the projects shown in the tree: those whose name
+// contains name, that aren't archived unless showArchived is set and, unless
+// activeSince is zero, that were active since.
+type projectFilter struct {
+	name         string
+	showArchived bool
+	activeSince  time.Time
+}
+
+// matches reports whether the project of node passes the filter.
+func (f projectFilter) matches(node *tview.TreeNode, ref *projectRef) bool {
+	if !matchesName(nodeName(node), f.name) || (ref.archived && !f.showArchived) {
+		return false
+	}
+	return f.activeSince.IsZero() || (ref.lastActivity != nil && ref.lastActivity.After(f.activeSince))
+}
+
+// matchesName reports whether name contains term, ignoring case.
+func matchesName(name, term string) bool {
+	return strings.Contains(strings.ToLower(name), strings.ToLower(term))
+}
+
+// filterProjects hides the projects below node that don't match filter.
+// Groups are always shown, with the number of archived projects hidden.
+func filterProjects(node *tview.TreeNode, filter projectFilter) {
+	children := node.GetChildren()
+	if ref, ok := node.GetReference().(*groupRef); ok {
+		children = nil
+		archived := 0
+		for _, child := range ref.children {
+			if project, isProject := child.GetReference().(*projectRef); isProject && !filter.matches(child, project) {
+				if project.archived && !filter.showArchived {
+					archived++
+				}
+				continue
+			}
+			children = append(children, child)
+		}
+		node.SetChildren(children)
+		setArchivedCount(node, ref, archived)
+	}
+
+	for _, child := range children {
+		filterProjects(child, filter)
+	}
+}
+
+// setArchivedCount tells in the text of a group node how many of its
+// archived projects are hidden, if any.
+func setArchivedCount(node *tview.TreeNode, ref *groupRef, archived int) {
+	text := " Group: " + ref.name
+	if archived > 0 {
+		text += fmt.Sprintf(" (%d archived hidden)", archived)
+	}
+	if strings.HasSuffix(node.GetText(), noAccessSuffix) {
+		text += noAccessSuffix
+	}
+	node.SetText(text)
+}
+
+// newInstanceNode returns the node of the instance the groups are below.
+func newInstanceNode() *tview.TreeNode {
+	return tview.NewTreeNode("󰮠 Instance: " + gitlabURL).
+		SetColor(themeColor(theme.Instance))
+}
+
+// listGroups fetches the groups matching searchTerm page by page, handing
+// the matching ones of every page to page along with how many groups were
+// fetched so far.
+func listGroups(ctx context.Context, gl GitLab, searchTerm string, page func(groups []*gitlab.Group, fetched int)) error {
+	fetched := 0
+	listOptions := &gitlab.ListGroupsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: perPage,
+			Page:    1,
+		},
+	}
+
+	// Without a search the tree starts at the top-level groups and subgroups
+	// are reached by expanding their parents; a search matches any depth.
+	if searchTerm == "" {
+		listOptions.TopLevelOnly = gitlab.Bool(true)
+	}
+
+	for {
+		groups, resp, err := gl.ListGroups(ctx, listOptions)
+		if err != nil {
+			return fmt.Errorf("Error fetching groups: %w", err)
+		}
+
+		var matching []*gitlab.Group
+		for _, group := range groups {
+			if searchTerm == "" || matchesName(group.Name, searchTerm) {
+				matching = append(matching, group)
+			}
+		}
+		fetched += len(groups)
+		page(matching, fetched)
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+
+	return nil
+}
+
+func newGroupNode(group *gitlab.Group) *tview.TreeNode {
+	return tview.NewTreeNode(" Group: " + group.Name).
+		SetColor(themeColor(theme.Group)).
+		SetReference(&groupRef{id: group.ID, name: group.Name}).
+		SetExpanded(false)
+}
+
+// newProjectNode returns the node of a project.
+func newProjectNode(project *gitlab.Project) *tview.TreeNode {
+	return tview.NewTreeNode("Project: " + project.Name).
+		SetColor(themeColor(theme.Project)).
+		SetReference(&projectRef{id: fmt.Sprintf("%d", project.ID), lastActivity: project.LastActivityAt, archived: project.Archived})
+}
+
+// buildMemberProjects is the alternative to listing all groups for
+// instances full of groups the user has nothing to do with: the projects the
+// user is a member of, below a node for each of their namespaces. The
+// namespaces are matched against searchTerm by their full path. As the
+// projects are all known, the namespace nodes don't fetch anything when
+// they're expanded.
+func buildMemberProjects(ctx context.Context, gl GitLab, searchTerm string) (*tview.TreeNode, error) {
+	root := newInstanceNode()
+
+	var allProjects []*gitlab.Project
+	listOptions := &gitlab.ListProjectsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: perPage,
+			Page:    1,
+		},
+		Membership: gitlab.Bool(true),
+	}
+
+	for {
+		projects, resp, err := gl.ListProjects(ctx, listOptions)
+		if err != nil {
+			return root, fmt.Errorf("Error fetching your projects: %w", err)
+		}
+
+		allProjects = append(allProjects, projects...)
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+
+	namespaces := make(map[string]*tview.TreeNode)
+	var paths []string
+	for _, project := range allProjects {
+		namespace := project.Namespace
+		if namespace == nil || (searchTerm != "" && !matchesName(namespace.FullPath, searchTerm)) {
+			continue
+		}
+
+		node, ok := namespaces[namespace.FullPath]
+		if !ok {
+			node = tview.NewTreeNode(" Group: " + namespace.FullPath).
+				SetColor(themeColor(theme.Group)).
+				SetReference(&groupRef{id: namespace.ID, name: namespace.FullPath, loaded: true}).
+				SetExpanded(false)
+			namespaces[namespace.FullPath] = node
+			paths = append(paths, namespace.FullPath)
+		}
+		ref := node.GetReference().(*groupRef)
+		ref.children = append(ref.children, newProjectNode(project))
+		node.SetChildren(ref.children)
+	}
+
+	sort.Strings(paths)
+	for _, path := range paths {
+		root.AddChild(namespaces[path])
+	}
+
+	return root, nil
+}
+
+// expandGroup toggles a group node, fetching its subgroups and projects in
+// the background the first time it's opened. Projects not matching filter
+// are hidden.
+func expandGroup(app *tview.Application, node *tview.TreeNode, filter projectFilter, returnTo tview.Primitive) {
+	ref := node.GetReference().(*groupRef)
+	if ref.loaded {
+		node.SetExpanded(!node.IsExpanded())
+		return
+	}
+
+	var children []*tview.TreeNode
+	loadAsync(app, "Loading group...", returnTo, func(ctx context.Context) error {
+		subgroups, err := subgroupNodes(ctx, gitlabClient, ref)
+		children = subgroups
+		if err != nil {
+			return err
+		}
+
+		projects, err := projectNodes(ctx, gitlabClient, ref)
+		children = append(children, projects...)
+		return err
+	}, func(err error) {
+		// Whatever was fetched is shown; after an error the next expand
+		// fetches everything again. Groups the user may not look into are
+		// marked instead, there's no point in asking again.
+		denied := isAccessDenied(err)
+		ref.loaded = err == nil || denied
+		ref.children = children
+		filterProjects(node, filter)
+		node.SetExpanded(true)
+		if denied {
+			markNoAccess(node)
+		}
+
+		app.SetRoot(returnTo, true)
+		if err != nil && !denied {
+			showError(app, err.Error(), returnTo)
+		}
+	})
+}
+
+// subgroupNodes returns a collapsed node for every subgroup of a group.
+func subgroupNodes(ctx context.Context, gl GitLab, ref *groupRef) ([]*tview.TreeNode, error) {
+	var allSubgroups []*gitlab.Group
+	listOptions := &gitlab.ListSubGroupsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: perPage,
+			Page:    1,
+		},
+	}
+
+	for {
+		subgroups, resp, err := gl.ListSubGroups(ctx, ref.id, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("Error fetching subgroups for group %s: %w", ref.name, err)
+		}
+
+		allSubgroups = append(allSubgroups, subgroups...)
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+
+	nodes := make([]*tview.TreeNode, 0, len(allSubgroups))
+	for _, subgroup := range allSubgroups {
+		nodes = append(nodes, newGroupNode(subgroup))
+	}
+
+	return nodes, nil
+}
+
+// projectNodes returns a node for every project of a group. On errors the
+// nodes for the projects fetched so far are returned along with the error.
+func projectNodes(ctx context.Context, gl GitLab, ref *groupRef) ([]*tview.TreeNode, error) {
+	var allProjects []*gitlab.Project
+	projectOptions := &gitlab.ListGroupProjectsOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: perPage,
+			Page:    1,
+		},
+	}
+
+	var projectsErr error
+	for {
+		projects, resp, err := gl.ListGroupProjects(ctx, ref.id, projectOptions)
+		if err != nil {
+			projectsErr = fmt.Errorf("Error fetching projects for group %s: %w", ref.name, err)
+			break
+		}
+
+		allProjects = append(allProjects, projects...)
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		projectOptions.Page = resp.NextPage
+	}
+
+	nodes := make([]*tview.TreeNode, 0, len(allProjects))
+	for _, project := range allProjects {
+		nodes = append(nodes, newProjectNode(project))
+	}
+
+	return nodes, projectsErr
+}
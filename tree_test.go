@@ -0,0 +1,343 @@
+// tree_test.go
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// fakeGitLab serves ListGroups from pages of groups. Calls it doesn't
+// implement panic through the nil embedded interface.
+type fakeGitLab struct {
+	GitLab
+
+	pages [][]*gitlab.Group
+	// failPage is the page whose request fails, 0 for none.
+	failPage int
+
+	requests []gitlab.ListGroupsOptions
+}
+
+func (f *fakeGitLab) ListGroups(ctx context.Context, opt *gitlab.ListGroupsOptions) ([]*gitlab.Group, *gitlab.Response, error) {
+	f.requests = append(f.requests, *opt)
+
+	page := opt.Page
+	if page == f.failPage {
+		return nil, nil, errors.New("boom")
+	}
+
+	resp := &gitlab.Response{CurrentPage: page, TotalPages: len(f.pages)}
+	if page < len(f.pages) {
+		resp.NextPage = page + 1
+	}
+	if page < 1 || page > len(f.pages) {
+		return nil, resp, nil
+	}
+	return f.pages[page-1], resp, nil
+}
+
+func groups(names ...string) []*gitlab.Group {
+	var groups []*gitlab.Group
+	for i, name := range names {
+		groups = append(groups, &gitlab.Group{ID: i + 1, Name: name})
+	}
+	return groups
+}
+
+func TestListGroups(t *testing.T) {
+	tests := []struct {
+		name       string
+		pages      [][]*gitlab.Group
+		failPage   int
+		searchTerm string
+		// wantPages are the names of the groups handed over per page.
+		wantPages   [][]string
+		wantFetched []int
+		wantErr     bool
+	}{
+		{
+			name:        "all groups",
+			pages:       [][]*gitlab.Group{groups("backend", "frontend")},
+			wantPages:   [][]string{{"backend", "frontend"}},
+			wantFetched: []int{2},
+		},
+		{
+			name:        "several pages",
+			pages:       [][]*gitlab.Group{groups("backend"), groups("frontend"), groups("infra")},
+			wantPages:   [][]string{{"backend"}, {"frontend"}, {"infra"}},
+			wantFetched: []int{1, 2, 3},
+		},
+		{
+			name:        "search ignores case",
+			pages:       [][]*gitlab.Group{groups("Backend", "frontend", "backend-tools")},
+			searchTerm:  "BACK",
+			wantPages:   [][]string{{"Backend", "backend-tools"}},
+			wantFetched: []int{3},
+		},
+		{
+			name:        "search without matches",
+			pages:       [][]*gitlab.Group{groups("backend", "frontend")},
+			searchTerm:  "infra",
+			wantPages:   [][]string{nil},
+			wantFetched: []int{2},
+		},
+		{
+			name:        "no groups",
+			pages:       [][]*gitlab.Group{nil},
+			wantPages:   [][]string{nil},
+			wantFetched: []int{0},
+		},
+		{
+			name:     "error on first page",
+			pages:    [][]*gitlab.Group{groups("backend")},
+			failPage: 1,
+			wantErr:  true,
+		},
+		{
+			name:        "error on later page",
+			pages:       [][]*gitlab.Group{groups("backend"), groups("frontend")},
+			failPage:    2,
+			wantPages:   [][]string{{"backend"}},
+			wantFetched: []int{1},
+			wantErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGitLab{pages: tt.pages, failPage: tt.failPage}
+
+			var gotPages [][]string
+			var gotFetched []int
+			err := listGroups(context.Background(), fake, tt.searchTerm, func(groups []*gitlab.Group, fetched int) {
+				var names []string
+				for _, group := range groups {
+					names = append(names, group.Name)
+				}
+				gotPages = append(gotPages, names)
+				gotFetched = append(gotFetched, fetched)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("listGroups() error = %v, want error %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(gotPages, tt.wantPages) {
+				t.Errorf("pages = %q, want %q", gotPages, tt.wantPages)
+			}
+			if !reflect.DeepEqual(gotFetched, tt.wantFetched) {
+				t.Errorf("fetched = %v, want %v", gotFetched, tt.wantFetched)
+			}
+
+			// Only a search reaches past the top-level groups.
+			for _, request := range fake.requests {
+				topLevelOnly := request.TopLevelOnly != nil && *request.TopLevelOnly
+				if topLevelOnly != (tt.searchTerm == "") {
+					t.Errorf("request with search %q has TopLevelOnly %v", tt.searchTerm, topLevelOnly)
+				}
+			}
+		})
+	}
+}
+
+func TestGroupNodes(t *testing.T) {
+	gitlabURL = "https://gitlab.example.com"
+
+	root := newInstanceNode()
+	if got, want := root.GetText(), "󰮠 Instance: "+gitlabURL; got != want {
+		t.Errorf("root text = %q, want %q", got, want)
+	}
+	if got, want := root.GetColor(), themeColor(theme.Instance); got != want {
+		t.Errorf("root color = %v, want %v", got, want)
+	}
+
+	for _, group := range groups("backend", "frontend") {
+		checkGroupNode(t, newGroupNode(group), group.Name)
+	}
+}
+
+func checkGroupNode(t *testing.T, node *tview.TreeNode, name string) {
+	t.Helper()
+
+	if got, want := node.GetText(), " Group: "+name; got != want {
+		t.Errorf("group text = %q, want %q", got, want)
+	}
+	if got, want := node.GetColor(), themeColor(theme.Group); got != want {
+		t.Errorf("group %s color = %v, want %v", name, got, want)
+	}
+	if node.IsExpanded() {
+		t.Errorf("group %s is expanded", name)
+	}
+	if len(node.GetChildren()) != 0 {
+		t.Errorf("group %s has %d children, want none", name, len(node.GetChildren()))
+	}
+
+	ref, ok := node.GetReference().(*groupRef)
+	if !ok {
+		t.Fatalf("group %s reference = %T, want *groupRef", name, node.GetReference())
+	}
+	if ref.name != name || ref.loaded {
+		t.Errorf("group %s reference = %+v", name, ref)
+	}
+}
+
+// testTree returns an instance node with a group "backend" holding the
+// projects api (active a day ago), worker (archived) and legacy (active a
+// year ago) and a subgroup "tools" holding the project cli.
+func testTree(now time.Time) *tview.TreeNode {
+	project := func(name string, activeAgo time.Duration, archived bool) *tview.TreeNode {
+		lastActivity := now.Add(-activeAgo)
+		return newProjectNode(&gitlab.Project{Name: name, LastActivityAt: &lastActivity, Archived: archived})
+	}
+	group := func(id int, name string, children ...*tview.TreeNode) *tview.TreeNode {
+		node := newGroupNode(&gitlab.Group{ID: id, Name: name})
+		ref := node.GetReference().(*groupRef)
+		ref.loaded, ref.children = true, children
+		return node.SetChildren(children)
+	}
+
+	tools := group(2, "tools", project("cli", time.Hour, false))
+	backend := group(1, "backend",
+		tools,
+		project("api", 24*time.Hour, false),
+		project("worker", time.Hour, true),
+		project("legacy", 365*24*time.Hour, false))
+	return newInstanceNode().AddChild(backend)
+}
+
+// nodeNames returns the names of the nodes below root, depth first.
+func nodeNames(root *tview.TreeNode) []string {
+	var names []string
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		if name := nodeName(node); name != "" {
+			names = append(names, name)
+		}
+		return true
+	})
+	return names
+}
+
+func TestFilterProjects(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		filter      projectFilter
+		want        []string
+		wantBackend string
+	}{
+		{
+			name:        "archived hidden",
+			want:        []string{"backend", "tools", "cli", "api", "legacy"},
+			wantBackend: " Group: backend (1 archived hidden)",
+		},
+		{
+			name:        "archived shown",
+			filter:      projectFilter{showArchived: true},
+			want:        []string{"backend", "tools", "cli", "api", "worker", "legacy"},
+			wantBackend: " Group: backend",
+		},
+		{
+			name:        "by name",
+			filter:      projectFilter{name: "L", showArchived: true},
+			want:        []string{"backend", "tools", "cli", "legacy"},
+			wantBackend: " Group: backend",
+		},
+		{
+			name:        "active only",
+			filter:      projectFilter{activeSince: now.Add(-30 * 24 * time.Hour)},
+			want:        []string{"backend", "tools", "cli", "api"},
+			wantBackend: " Group: backend (1 archived hidden)",
+		},
+		{
+			name:        "nothing matches",
+			filter:      projectFilter{name: "nope"},
+			want:        []string{"backend", "tools"},
+			wantBackend: " Group: backend (1 archived hidden)",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			root := testTree(now)
+			filterProjects(root, tt.filter)
+
+			if got := nodeNames(root); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shown = %v, want %v", got, tt.want)
+			}
+			if got := root.GetChildren()[0].GetText(); got != tt.wantBackend {
+				t.Errorf("backend text = %q, want %q", got, tt.wantBackend)
+			}
+		})
+	}
+
+	t.Run("filters are undone", func(t *testing.T) {
+		root := testTree(now)
+		filterProjects(root, projectFilter{name: "nope"})
+		filterProjects(root, projectFilter{showArchived: true})
+
+		want := []string{"backend", "tools", "cli", "api", "worker", "legacy"}
+		if got := nodeNames(root); !reflect.DeepEqual(got, want) {
+			t.Errorf("shown = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestSearchTree(t *testing.T) {
+	root := testTree(time.Now())
+	byName := make(map[string]*tview.TreeNode)
+	root.Walk(func(node, parent *tview.TreeNode) bool {
+		byName[nodeName(node)] = node
+		return true
+	})
+
+	tests := []struct {
+		name        string
+		current     string
+		term        string
+		step        int
+		wantMatches []string
+		want        string
+	}{
+		{name: "no term", current: "api", step: 1},
+		{name: "no match", current: "api", term: "nope", step: 1},
+		{name: "current matches", current: "cli", term: "I", wantMatches: []string{"cli", "api"}, want: "cli"},
+		{name: "next after current", current: "tools", term: "I", wantMatches: []string{"cli", "api"}, want: "cli"},
+		{name: "next match", current: "cli", term: "I", step: 1, wantMatches: []string{"cli", "api"}, want: "api"},
+		{name: "wraps forward", current: "api", term: "I", step: 1, wantMatches: []string{"cli", "api"}, want: "cli"},
+		{name: "previous match", current: "api", term: "I", step: -1, wantMatches: []string{"cli", "api"}, want: "cli"},
+		{name: "wraps backward", current: "cli", term: "I", step: -1, wantMatches: []string{"cli", "api"}, want: "api"},
+		{name: "groups match too", current: "api", term: "o", step: 1, wantMatches: []string{"tools", "worker"}, want: "worker"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			matches, next := searchTree(root, byName[tt.current], tt.term, tt.step)
+
+			var got []string
+			for _, match := range matches {
+				got = append(got, nodeName(match))
+			}
+			if !reflect.DeepEqual(got, tt.wantMatches) {
+				t.Errorf("matches = %v, want %v", got, tt.wantMatches)
+			}
+			if tt.want == "" {
+				if next != nil {
+					t.Errorf("next = %q, want none", nodeName(next))
+				}
+				return
+			}
+			if next != byName[tt.want] {
+				t.Errorf("next = %q, want %q", nodeName(next), tt.want)
+			}
+		})
+	}
+}
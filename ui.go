@@ -0,0 +1,448 @@
+// ui.go
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// statusColor returns the tview color tag used to render a job or pipeline
+// status.
+func statusColor(status string) string {
+	switch status {
+	case "success":
+		return colorTag(theme.Success)
+	case "failed":
+		return colorTag(theme.Failed)
+	case "running":
+		return colorTag(theme.Running)
+	case "canceled", "skipped":
+		return colorTag(theme.Canceled)
+	case "manual":
+		return colorTag(theme.Manual)
+	case "warning":
+		return colorTag(theme.Warning)
+	}
+	return "[-]"
+}
+
+// jobStatusText returns the colored status of a job. Failed jobs that are
+// allowed to fail don't fail their pipeline, so they stand out differently.
+func jobStatusText(job *gitlab.Job) string {
+	if job.Status == "failed" && job.AllowFailure {
+		return statusColor("warning") + "failed (allowed)[-]"
+	}
+	return statusColor(job.Status) + job.Status + "[-]"
+}
+
+// isTerminalStatus reports whether a job or pipeline status is final.
+func isTerminalStatus(status string) bool {
+	switch status {
+	case "success", "failed", "canceled", "skipped":
+		return true
+	}
+	return false
+}
+
+// humanizeDuration formats a duration in seconds as reported by the API,
+// e.g. 83.4 as "1m23s".
+func humanizeDuration(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second).String()
+}
+
+// toInt parses an ID kept as a string, such as a node reference.
+func toInt(s string) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID %q", s)
+	}
+	return i, nil
+}
+
+// buildFooter returns the key hint bar shown at the bottom of the main views,
+// followed by who the user is logged in as and where, flagged when the
+// certificate of the instance isn't checked. back may be nil for views that
+// have nowhere to go back to.
+func buildFooter(app *tview.Application, back func()) *tview.Flex {
+	footer := tview.NewFlex()
+	if back != nil {
+		footer.AddItem(tview.NewButton("ESC - Back").SetSelectedFunc(back), 0, 1, false)
+	}
+	footer.AddItem(tview.NewButton("Q - Quit").SetSelectedFunc(app.Stop), 0, 1, false)
+
+	text := fmt.Sprintf("%s%s[-] @ %s ", colorTag(theme.Accent), tview.Escape(currentUser), tview.Escape(gitlabURL))
+	if insecureTLS {
+		text = boldColorTag(theme.Failed) + "INSECURE TLS[-::-] " + text
+	}
+	status := tview.NewTextView().
+		SetDynamicColors(true).
+		SetTextAlign(tview.AlignRight).
+		SetText(text)
+	footer.AddItem(status, 0, 1, false)
+
+	return footer
+}
+
+// buildEmptyMessage returns a view with text centered in it, shown in place
+// of a list that has no items.
+func buildEmptyMessage(text string) *tview.Flex {
+	return tview.NewFlex().
+		SetDirection(tview.FlexRow).
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewTextView().SetTextAlign(tview.AlignCenter).SetText(text), 1, 0, false).
+		AddItem(nil, 0, 1, false)
+}
+
+// views is the stack of views the user navigated through, with the current
+// view on top. Loading, error and action modals are shown over the current
+// view without being pushed.
+var views []tview.Primitive
+
+// pushView shows view and puts it on top of the view stack, unless it's
+// already the current view.
+func pushView(app *tview.Application, view tview.Primitive) {
+	if len(views) == 0 || views[len(views)-1] != view {
+		views = append(views, view)
+	}
+	app.SetRoot(view, true)
+}
+
+// popView goes back to the previous view as it was left. The first view is
+// never popped.
+func popView(app *tview.Application) {
+	if len(views) > 1 {
+		views = views[:len(views)-1]
+	}
+	app.SetRoot(views[len(views)-1], true)
+}
+
+// isOpen reports whether view is on the view stack, i.e. it wasn't left
+// for good.
+func isOpen(view tview.Primitive) bool {
+	for _, v := range views {
+		if v == view {
+			return true
+		}
+	}
+	return false
+}
+
+// breadcrumb is the bar at the top of the main views showing the path to the
+// current view, e.g. "gitlab.com › mygroup › myproject › main".
+type breadcrumb struct {
+	*tview.TextView
+	crumbs []string
+}
+
+// buildBreadcrumb returns the breadcrumb of a view. Clicking a crumb goes
+// back to the view it stands for.
+func buildBreadcrumb(app *tview.Application, crumbs []string) *breadcrumb {
+	var text strings.Builder
+	for i, crumb := range crumbs {
+		if i > 0 {
+			text.WriteString(" › ")
+		}
+		fmt.Fprintf(&text, `["%d"]%s[""]`, i, tview.Escape(crumb))
+	}
+
+	bar := &breadcrumb{
+		TextView: tview.NewTextView().
+			SetDynamicColors(true).
+			SetRegions(true).
+			SetText(colorTag(theme.Accent) + text.String()),
+		crumbs: crumbs,
+	}
+
+	// A click highlights the region of the crumb.
+	bar.SetHighlightedFunc(func(added, removed, remaining []string) {
+		if len(added) == 0 {
+			return
+		}
+		bar.Highlight()
+		if index, err := strconv.Atoi(added[0]); err == nil {
+			goToCrumb(app, index)
+		}
+	})
+	return bar
+}
+
+// viewBreadcrumb returns the breadcrumb of a view, or nil for views without
+// one such as the start menu.
+func viewBreadcrumb(view tview.Primitive) *breadcrumb {
+	flex, ok := view.(*tview.Flex)
+	if !ok || flex.GetItemCount() == 0 {
+		return nil
+	}
+	bar, _ := flex.GetItem(0).(*breadcrumb)
+	return bar
+}
+
+// goToCrumb pops the views down to the one crumb number index of the
+// current view stands for: the topmost one whose path ends there or above.
+// Groups share the view of the tree, so they all lead back to it.
+func goToCrumb(app *tview.Application, index int) {
+	for i := len(views) - 2; i >= 0; i-- {
+		if bar := viewBreadcrumb(views[i]); bar != nil && len(bar.crumbs) <= index+1 {
+			views = views[:i+1]
+			app.SetRoot(views[i], true)
+			return
+		}
+	}
+}
+
+// showCrumbs lets the user pick a crumb of the current view to go back to,
+// the keyboard way of clicking one.
+func showCrumbs(app *tview.Application) {
+	// Before the start menu there is nothing to go back to.
+	if len(views) == 0 {
+		return
+	}
+	current := views[len(views)-1]
+	bar := viewBreadcrumb(current)
+	if bar == nil || len(bar.crumbs) < 2 {
+		return
+	}
+
+	buttons := withCrumb(bar.crumbs[:len(bar.crumbs)-1], "Cancel")
+	modal := tview.NewModal().
+		SetText("Go back to").
+		AddButtons(buttons).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			app.SetRoot(current, true)
+			if buttonIndex >= 0 && buttonIndex < len(buttons)-1 {
+				goToCrumb(app, buttonIndex)
+			}
+		})
+
+	app.SetRoot(modal, false).SetFocus(modal)
+}
+
+// withCrumb returns crumbs extended by crumb. crumbs is never modified, so
+// views sharing a parent don't overwrite each other's paths.
+func withCrumb(crumbs []string, crumb ...string) []string {
+	return append(crumbs[:len(crumbs):len(crumbs)], crumb...)
+}
+
+// instanceCrumb is the first crumb of every path: the host of the instance.
+func instanceCrumb() string {
+	if u, err := url.Parse(gitlabURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return gitlabURL
+}
+
+const defaultLoadTimeout = time.Minute
+
+// loadTimeout is how long loadAsync lets a fetch take, 0 for no limit.
+var loadTimeout = defaultLoadTimeout
+
+// loadAsync shows a loading modal with msg and runs fetch on its own
+// goroutine. When fetch returns, done is called with its error on the UI
+// goroutine. Canceling the modal aborts the request through its context and
+// restores returnTo; done is not called in that case. Neither is it when
+// fetch fails for taking longer than loadTimeout; the user may retry instead.
+func loadAsync(app *tview.Application, msg string, returnTo tview.Primitive, fetch func(ctx context.Context) error, done func(err error)) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if loadTimeout > 0 {
+		ctx, cancel = context.WithTimeout(context.Background(), loadTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(context.Background())
+	}
+
+	modal := tview.NewModal().
+		SetText(msg).
+		AddButtons([]string{"Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			cancel()
+			app.SetRoot(returnTo, true)
+		})
+
+	app.SetRoot(modal, false).SetFocus(modal)
+
+	ctx = context.WithValue(ctx, statusKey{}, func(status string) {
+		app.QueueUpdateDraw(func() {
+			modal.SetText(status)
+		})
+	})
+
+	go func() {
+		err := fetch(ctx)
+		app.QueueUpdateDraw(func() {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return
+			}
+			timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded)
+			cancel()
+
+			if err != nil && timedOut {
+				showTimeout(app, func() {
+					loadAsync(app, msg, returnTo, fetch, done)
+				}, returnTo)
+				return
+			}
+			done(err)
+		})
+	}()
+}
+
+// showTimeout tells that GitLab didn't answer in time, offering to retry
+// or to go back to returnTo.
+func showTimeout(app *tview.Application, retry func(), returnTo tview.Primitive) {
+	logError("GitLab didn't answer within %s", loadTimeout)
+	modal := tview.NewModal().
+		SetText(fmt.Sprintf("GitLab didn't answer within %s.", loadTimeout)).
+		AddButtons([]string{"Retry", "Back"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Retry" {
+				retry()
+				return
+			}
+			app.SetRoot(returnTo, true)
+		})
+
+	app.SetRoot(modal, false).SetFocus(modal)
+}
+
+// keyHelp describes a keyboard shortcut for the help overlay.
+type keyHelp struct {
+	key    string
+	action string
+}
+
+// globalKeys are the shortcuts that work in every view.
+var globalKeys = []keyHelp{
+	{"?", "Show this help"},
+	{"Ctrl-B", "Go back to a crumb of the path"},
+	{"Ctrl-L", "Show or hide the event log"},
+	{"q", "Quit"},
+	{"Ctrl-C", "Quit"},
+}
+
+// showHelp lists the shortcuts of the current view, followed by the global
+// ones, in a modal that restores returnTo when dismissed.
+func showHelp(app *tview.Application, keys []keyHelp, returnTo tview.Primitive) {
+	var text strings.Builder
+	for _, k := range append(keys[:len(keys):len(keys)], globalKeys...) {
+		fmt.Fprintf(&text, "%-10s %s\n", k.key, k.action)
+	}
+
+	showMessage(app, text.String(), returnTo)
+}
+
+type statusKey struct{}
+
+// reportStatus replaces the message of the loading modal of the loadAsync
+// call ctx comes from. It does nothing for other contexts.
+func reportStatus(ctx context.Context, status string) {
+	if report, ok := ctx.Value(statusKey{}).(func(string)); ok {
+		report(status)
+	}
+}
+
+// copyToClipboard copies text to the system clipboard and confirms it in a
+// modal that restores returnTo. what describes text in the confirmation.
+func copyToClipboard(app *tview.Application, text, what string, returnTo tview.Primitive) {
+	if err := clipboard.WriteAll(text); err != nil {
+		showError(app, fmt.Sprintf("Error copying to clipboard: %v", err), returnTo)
+		return
+	}
+
+	showMessage(app, fmt.Sprintf("Copied %s to the clipboard", what), returnTo)
+}
+
+// ringBell rings the terminal bell. tcell owns the terminal, so the bell
+// goes through its screen, which is only handed out while drawing: the next
+// draw rings it.
+func ringBell(app *tview.Application) {
+	app.QueueUpdateDraw(func() {
+		app.SetBeforeDrawFunc(func(screen tcell.Screen) bool {
+			app.SetBeforeDrawFunc(nil)
+			screen.Beep()
+			return false
+		})
+	})
+}
+
+// openInBrowser opens webURL in the default browser. Where there is no browser
+// to open, such as over SSH, the URL is shown instead. Either way returnTo is
+// restored.
+func openInBrowser(app *tview.Application, webURL string, returnTo tview.Primitive) {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", webURL)
+	case "windows":
+		cmd = exec.Command("cmd", "/c", "start", "", webURL)
+	default:
+		if os.Getenv("DISPLAY") != "" || os.Getenv("WAYLAND_DISPLAY") != "" {
+			cmd = exec.Command("xdg-open", webURL)
+		}
+	}
+
+	if cmd == nil || cmd.Start() != nil {
+		showMessage(app, "Open this URL in a browser:\n"+webURL, returnTo)
+		return
+	}
+
+	// The opener is left to finish on its own.
+	go cmd.Wait()
+	app.SetRoot(returnTo, true)
+}
+
+// confirmActions asks before actions like retrying or canceling are run.
+var confirmActions = true
+
+// confirm asks the user to confirm prompt and calls yes if they do. Saying
+// no restores returnTo. Without confirmActions yes is called right away.
+func confirm(app *tview.Application, prompt string, returnTo tview.Primitive, yes func()) {
+	if !confirmActions {
+		yes()
+		return
+	}
+
+	modal := tview.NewModal().
+		SetText(prompt).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			if buttonLabel == "Yes" {
+				yes()
+				return
+			}
+			app.SetRoot(returnTo, true)
+		})
+
+	app.SetRoot(modal, false).SetFocus(modal)
+}
+
+// showError replaces the current root with a modal describing msg and logs
+// it. Dismissing the modal (OK, Enter or Esc) restores returnTo as the root.
+func showError(app *tview.Application, msg string, returnTo tview.Primitive) {
+	logError("%s", msg)
+	showMessage(app, msg, returnTo)
+}
+
+// showMessage shows msg in a modal with an OK button that restores returnTo.
+func showMessage(app *tview.Application, msg string, returnTo tview.Primitive) {
+	modal := tview.NewModal().
+		SetText(msg).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			app.SetRoot(returnTo, true)
+		})
+
+	app.SetRoot(modal, false).SetFocus(modal)
+}
@@ -0,0 +1,129 @@
+// variables.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/xanzy/go-gitlab"
+)
+
+// variableKeys are the shortcuts of the variable view.
+var variableKeys = []keyHelp{
+	{"Esc", "Back"},
+}
+
+// fetchAndShowVariables shows the variables a pipeline was run with. crumbs
+// is the breadcrumb of the pipeline.
+func fetchAndShowVariables(app *tview.Application, projectID string, pipelineID int, crumbs []string, returnTo tview.Primitive) {
+	var variables []*gitlab.PipelineVariable
+	var masked map[string]bool
+	loadAsync(app, "Loading variables...", returnTo, func(ctx context.Context) (err error) {
+		variables, _, err = gitlabClient.GetPipelineVariables(ctx, projectID, pipelineID)
+		if err != nil {
+			return fmt.Errorf("Error fetching variables for pipeline %d: %w", pipelineID, err)
+		}
+		masked = maskedVariables(ctx, gitlabClient, projectID)
+		return nil
+	}, func(err error) {
+		if err != nil {
+			showError(app, err.Error(), returnTo)
+			return
+		}
+
+		if len(variables) == 0 {
+			showMessage(app, fmt.Sprintf("Pipeline %d was run without variables.", pipelineID), returnTo)
+			return
+		}
+
+		showVariables(app, variables, masked, withCrumb(crumbs, "variables"))
+	})
+}
+
+// maskedVariables returns the keys of the masked CI/CD variables of a
+// project. The variables of a pipeline don't tell whether they're masked, so
+// their values are hidden where the project masks a variable of the same
+// key. Listing the variables of a project takes the maintainer role; without
+// it nothing is masked.
+func maskedVariables(ctx context.Context, gl GitLab, projectID string) map[string]bool {
+	masked := make(map[string]bool)
+	listOptions := &gitlab.ListProjectVariablesOptions{
+		PerPage: perPage,
+		Page:    1,
+	}
+
+	for {
+		page, resp, err := gl.ListProjectVariables(ctx, projectID, listOptions)
+		if err != nil {
+			return masked
+		}
+
+		for _, variable := range page {
+			if variable.Masked {
+				masked[variable.Key] = true
+			}
+		}
+
+		if resp.CurrentPage >= resp.TotalPages {
+			break
+		}
+		listOptions.Page = resp.NextPage
+	}
+
+	return masked
+}
+
+// showVariables lists variables as key/value pairs, hiding the values of the
+// masked ones.
+func showVariables(app *tview.Application, variables []*gitlab.PipelineVariable, masked map[string]bool, crumbs []string) {
+	width := 0
+	for _, variable := range variables {
+		if len(variable.Key) > width {
+			width = len(variable.Key)
+		}
+	}
+
+	var text strings.Builder
+	for _, variable := range variables {
+		value := tview.Escape(variable.Value)
+		if masked[variable.Key] {
+			value = "[::d]masked[::-]"
+		}
+		if variable.VariableType == "file" {
+			value += " [::d](file)[::-]"
+		}
+		fmt.Fprintf(&text, "%s%-*s[-] = %s\n", colorTag(theme.Accent), width, tview.Escape(variable.Key), value)
+	}
+
+	variableView := tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetText(text.String())
+	variableView.SetBorder(true).SetTitle(fmt.Sprintf(" Variables (%d) ", len(variables)))
+
+	flex := tview.NewFlex()
+
+	variableView.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			popView(app)
+			return nil
+		case event.Key() == tcell.KeyRune && event.Rune() == '?':
+			showHelp(app, variableKeys, flex)
+			return nil
+		}
+		return event
+	})
+
+	flex.SetDirection(tview.FlexRow).
+		AddItem(buildBreadcrumb(app, crumbs), 1, 0, false).
+		AddItem(variableView, 0, 1, true).
+		AddItem(buildFooter(app, func() {
+			popView(app)
+		}), 1, 0, false)
+
+	pushView(app, flex)
+}
@@ -0,0 +1,91 @@
+// watch.go
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gen2brain/beeep"
+	"github.com/rivo/tview"
+)
+
+const watchInterval = 10 * time.Second
+
+// watchBell rings the terminal bell when a watched pipeline finishes.
+var watchBell bool
+
+// watchedPipelines are the pipelines being watched, by project and pipeline
+// ID. It's only used on the UI goroutine.
+var watchedPipelines = make(map[string]bool)
+
+// watchPipeline polls the status of a pipeline in the background and sends a
+// desktop notification once it finishes. The confirmation is shown over
+// returnTo.
+func watchPipeline(app *tview.Application, projectID string, pipelineID int, returnTo tview.Primitive) {
+	key := fmt.Sprintf("%s/%d", projectID, pipelineID)
+	if watchedPipelines[key] {
+		showMessage(app, fmt.Sprintf("Pipeline %d is already being watched.", pipelineID), returnTo)
+		return
+	}
+	watchedPipelines[key] = true
+
+	// The watch sticks to the instance it was started on.
+	gl, instance := gitlabClient, gitlabURL
+	go func() {
+		status, err := pollPipeline(gl, projectID, pipelineID)
+		app.QueueUpdate(func() {
+			delete(watchedPipelines, key)
+		})
+
+		// The notification is all there is to do, so there is nowhere to
+		// report it failing.
+		title := fmt.Sprintf("Pipeline %d %s", pipelineID, status)
+		if err != nil {
+			logError("Stopped watching pipeline %d of project %s: %v", pipelineID, projectID, err)
+			title = fmt.Sprintf("Stopped watching pipeline %d", pipelineID)
+		} else {
+			logEvent("Watched pipeline %d of project %s is %s", pipelineID, projectID, status)
+		}
+		_ = beeep.Notify(title, fmt.Sprintf("Project %s on %s", projectID, instance), "")
+		if watchBell {
+			ringBell(app)
+		}
+	}()
+
+	showMessage(app, fmt.Sprintf("Watching pipeline %d. You'll be notified when it finishes.", pipelineID), returnTo)
+}
+
+// maxWatchFailures is how many polls of a watched pipeline in a row may fail
+// before the watch is given up.
+const maxWatchFailures = 5
+
+// pollPipeline waits for a pipeline to finish or to wait for a manual job and
+// returns its status then. Failed requests are retried on the next poll,
+// unless the pipeline is gone or may no longer be read, or maxWatchFailures
+// polls in a row failed; the last error is returned then.
+func pollPipeline(gl GitLab, projectID string, pipelineID int) (string, error) {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		<-ticker.C
+
+		pipeline, resp, err := gl.GetPipeline(context.Background(), projectID, pipelineID)
+		if err != nil {
+			failures++
+			if isAccessDenied(err) || (resp != nil && resp.StatusCode == http.StatusNotFound) || failures >= maxWatchFailures {
+				return "", err
+			}
+			logError("Error polling watched pipeline %d: %v", pipelineID, err)
+			continue
+		}
+		failures = 0
+
+		if isTerminalStatus(pipeline.Status) || pipeline.Status == "manual" {
+			return pipeline.Status, nil
+		}
+	}
+}